@@ -0,0 +1,140 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/rand"
+	"fmt"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+// benchmarkPayloadSizes are the payload sizes used to compare the standard v4
+// (BLAKE2b) and the experimental v4x (BLAKE3) local tokens throughput. The
+// MB/s column is computed from the payload size:
+//
+//	go test -run '^$' -bench 'Benchmark_Local_V4VsV4x' .
+var benchmarkPayloadSizes = []int{64, 1 << 10, 64 << 10, 1 << 20}
+
+func TestLocalVariants_RoundTrip(t *testing.T) {
+	for _, v := range localVariants(t) {
+		for _, size := range benchmarkPayloadSizes {
+			m := make([]byte, size)
+
+			token, err := v.encrypt(m)
+			assert.NoError(t, err, v.name)
+			p, err := v.decrypt(token)
+			assert.NoError(t, err, v.name)
+			assert.Equal(t, m, p, v.name)
+		}
+	}
+}
+
+func Benchmark_Local_V4VsV4x_Encrypt(b *testing.B) {
+	for _, v := range localVariants(b) {
+		for _, size := range benchmarkPayloadSizes {
+			m := make([]byte, size)
+
+			b.Run(fmt.Sprintf("%s/%s", v.name, sizeLabel(size)), func(b *testing.B) {
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for n := 0; n < b.N; n++ {
+					if _, err := v.encrypt(m); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+func Benchmark_Local_V4VsV4x_Decrypt(b *testing.B) {
+	for _, v := range localVariants(b) {
+		for _, size := range benchmarkPayloadSizes {
+			token, err := v.encrypt(make([]byte, size))
+			assert.NoError(b, err)
+
+			b.Run(fmt.Sprintf("%s/%s", v.name, sizeLabel(size)), func(b *testing.B) {
+				b.SetBytes(int64(size))
+				b.ReportAllocs()
+				b.ResetTimer()
+
+				for n := 0; n < b.N; n++ {
+					if _, err := v.decrypt(token); err != nil {
+						b.Fatal(err)
+					}
+				}
+			})
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type localVariant struct {
+	name    string
+	encrypt func(m []byte) (string, error)
+	decrypt func(token string) ([]byte, error)
+}
+
+func localVariants(tb testing.TB) []localVariant {
+	tb.Helper()
+
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(tb, err)
+	k4x, err := pasetov4x.GenerateLocalKey(rand.Reader)
+	assert.NoError(tb, err)
+
+	return []localVariant{
+		{
+			name: "v4",
+			encrypt: func(m []byte) (string, error) {
+				return pasetov4.Encrypt(rand.Reader, k4, m, nil, nil)
+			},
+			decrypt: func(token string) ([]byte, error) {
+				return pasetov4.Decrypt(k4, token, nil, nil)
+			},
+		},
+		{
+			name: "v4x",
+			encrypt: func(m []byte) (string, error) {
+				return pasetov4x.Encrypt(rand.Reader, k4x, m, nil, nil)
+			},
+			decrypt: func(token string) ([]byte, error) {
+				return pasetov4x.Decrypt(k4x, token, nil, nil)
+			},
+		},
+	}
+}
+
+func sizeLabel(size int) string {
+	switch {
+	case size >= 1<<20:
+		return fmt.Sprintf("%dMB", size>>20)
+	case size >= 1<<10:
+		return fmt.Sprintf("%dKB", size>>10)
+	default:
+		return fmt.Sprintf("%dB", size)
+	}
+}
@@ -0,0 +1,138 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Audience represents the `aud` claim. It is decoded from either a single
+// string or an array of strings, and encoded as a string when it contains a
+// single value.
+type Audience []string
+
+// Contains returns true if the audience set contains the given value.
+func (a Audience) Contains(value string) bool {
+	for _, v := range a {
+		if v == value {
+			return true
+		}
+	}
+
+	return false
+}
+
+// ContainsFunc returns true if one of the audience values satisfies match.
+func (a Audience) ContainsFunc(match func(string) bool) bool {
+	for _, v := range a {
+		if match(v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// MarshalJSON encodes the audience as a string or an array of strings.
+func (a Audience) MarshalJSON() ([]byte, error) {
+	if len(a) == 1 {
+		return json.Marshal(a[0])
+	}
+
+	return json.Marshal([]string(a))
+}
+
+// UnmarshalJSON decodes the audience from a string or an array of strings.
+func (a *Audience) UnmarshalJSON(data []byte) error {
+	// Single value
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*a = Audience{single}
+		return nil
+	}
+
+	// Multiple values
+	var multiple []string
+	if err := json.Unmarshal(data, &multiple); err != nil {
+		return errors.New("paseto: invalid audience, it must be a string or an array of strings")
+	}
+	*a = Audience(multiple)
+
+	// No error
+	return nil
+}
+
+// ErrInvalidAudience is raised when the token audience doesn't match.
+var ErrInvalidAudience = errors.New("paseto: invalid token audience")
+
+// WithAudience checks that the token audience contains the given value.
+func WithAudience(a string) Rule {
+	return func(p *Parser) {
+		p.checks = append(p.checks, func(c *Claims) error {
+			if !c.Audience.Contains(a) {
+				return ErrInvalidAudience
+			}
+			return nil
+		})
+	}
+}
+
+// WithAudienceMatcher checks that one of the token audience values satisfies
+// the given matcher, to accept a family of audiences with a single rule.
+//
+//	WithAudienceMatcher(MatchAudiencePattern("api.*.example.com"))
+//	WithAudienceMatcher(func(aud string) bool { return strings.HasPrefix(aud, "api-") })
+func WithAudienceMatcher(match func(aud string) bool) Rule {
+	return func(p *Parser) {
+		if match == nil {
+			p.setError(errors.New("paseto: audience matcher must not be nil"))
+			return
+		}
+		p.checks = append(p.checks, func(c *Claims) error {
+			if !c.Audience.ContainsFunc(match) {
+				return ErrInvalidAudience
+			}
+			return nil
+		})
+	}
+}
+
+// MatchAudiencePattern returns a matcher for dot separated audiences where a
+// `*` label matches exactly one non-empty label: `api.*.example.com` matches
+// `api.staging.example.com` but neither `api.example.com` nor
+// `api.a.b.example.com`.
+func MatchAudiencePattern(pattern string) func(aud string) bool {
+	labels := strings.Split(pattern, ".")
+	return func(aud string) bool {
+		values := strings.Split(aud, ".")
+		if len(values) != len(labels) {
+			return false
+		}
+		for j, label := range labels {
+			switch {
+			case label == "*" && values[j] != "":
+			case label == values[j]:
+			default:
+				return false
+			}
+		}
+		return true
+	}
+}
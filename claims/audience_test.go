@@ -0,0 +1,106 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAudience_JSON(t *testing.T) {
+	var c Claims
+	assert.NoError(t, json.Unmarshal([]byte(`{"aud":"api"}`), &c))
+	assert.Equal(t, Audience{"api"}, c.Audience)
+
+	assert.NoError(t, json.Unmarshal([]byte(`{"aud":["api","admin"]}`), &c))
+	assert.Equal(t, Audience{"api", "admin"}, c.Audience)
+
+	assert.Error(t, json.Unmarshal([]byte(`{"aud":123}`), &c))
+
+	raw, err := json.Marshal(Claims{Audience: Audience{"api"}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"aud":"api"}`, string(raw))
+
+	raw, err = json.Marshal(Claims{Audience: Audience{"api", "admin"}})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"aud":["api","admin"]}`, string(raw))
+
+	raw, err = json.Marshal(Claims{})
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{}`, string(raw))
+}
+
+func TestWithAudience(t *testing.T) {
+	p := NewParser(WithAudience("api"))
+
+	_, err := p.Parse([]byte(`{"aud":"api"}`))
+	assert.NoError(t, err)
+
+	_, err = p.Parse([]byte(`{"aud":["admin","api"]}`))
+	assert.NoError(t, err)
+
+	_, err = p.Parse([]byte(`{"aud":["admin"]}`))
+	assert.ErrorIs(t, err, ErrInvalidAudience)
+
+	_, err = p.Parse([]byte(`{}`))
+	assert.ErrorIs(t, err, ErrInvalidAudience)
+}
+
+func TestWithAudienceMatcher(t *testing.T) {
+	testCases := []struct {
+		name    string
+		pattern string
+		payload string
+		wantErr bool
+	}{
+		{name: "wildcard label", pattern: "api.*.example.com", payload: `{"aud":"api.staging.example.com"}`},
+		{name: "one of values", pattern: "api.*.example.com", payload: `{"aud":["admin","api.prod.example.com"]}`},
+		{name: "exact", pattern: "api.example.com", payload: `{"aud":"api.example.com"}`},
+		{name: "missing label", pattern: "api.*.example.com", payload: `{"aud":"api.example.com"}`, wantErr: true},
+		{name: "empty label", pattern: "api.*.example.com", payload: `{"aud":"api..example.com"}`, wantErr: true},
+		{name: "extra label", pattern: "api.*.example.com", payload: `{"aud":"api.a.b.example.com"}`, wantErr: true},
+		{name: "other domain", pattern: "api.*.example.com", payload: `{"aud":"api.staging.example.org"}`, wantErr: true},
+		{name: "no audience", pattern: "api.*.example.com", payload: `{}`, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewParser(WithAudienceMatcher(MatchAudiencePattern(testCase.pattern))).Parse([]byte(testCase.payload))
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidAudience)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	// Custom prefix matcher
+	p := NewParser(WithAudienceMatcher(func(aud string) bool { return strings.HasPrefix(aud, "api-") }))
+	_, err := p.Parse([]byte(`{"aud":"api-eu"}`))
+	assert.NoError(t, err)
+	_, err = p.Parse([]byte(`{"aud":"web-eu"}`))
+	assert.ErrorIs(t, err, ErrInvalidAudience)
+
+	// Nil matcher
+	_, err = NewParser(WithAudienceMatcher(nil)).Parse([]byte(`{}`))
+	assert.Error(t, err)
+}
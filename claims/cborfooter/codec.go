@@ -0,0 +1,43 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package cborfooter provides a CBOR footer codec for claims.WithFooterCodec.
+//
+// It lives in its own module so that the CBOR library is only pulled by the
+// applications using it.
+package cborfooter
+
+import (
+	"github.com/fxamacker/cbor/v2"
+
+	"zntr.io/paseto/claims"
+)
+
+// Codec encodes footers as CBOR maps (RFC 8949) for compact binary footers.
+var Codec claims.FooterCodec = codec{}
+
+// -----------------------------------------------------------------------------
+
+type codec struct{}
+
+func (codec) Marshal(v any) ([]byte, error) {
+	return cbor.Marshal(v)
+}
+
+func (codec) Unmarshal(data []byte, v any) error {
+	return cbor.Unmarshal(data, v)
+}
@@ -0,0 +1,76 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cborfooter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"zntr.io/paseto/claims"
+)
+
+func TestCodec_ParseFooter(t *testing.T) {
+	footer := &claims.Footer{KeyID: "zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN", KeyGeneration: 2}
+
+	raw, err := Codec.Marshal(footer)
+	assert.NoError(t, err)
+
+	out, err := claims.NewParser(claims.WithFooterCodec(Codec)).ParseFooter(raw)
+	assert.NoError(t, err)
+	assert.Equal(t, footer, out)
+}
+
+func TestCodec_IsCompact(t *testing.T) {
+	footer := &claims.Footer{KeyID: "zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}
+
+	rawJSON, err := claims.JSONFooterCodec.Marshal(footer)
+	assert.NoError(t, err)
+	rawCBOR, err := Codec.Marshal(footer)
+	assert.NoError(t, err)
+	assert.Less(t, len(rawCBOR), len(rawJSON))
+
+	// Default codec is JSON
+	_, err = claims.NewParser().ParseFooter(rawCBOR)
+	assert.Error(t, err)
+}
+
+func TestCodec_MinKeyGeneration(t *testing.T) {
+	testCases := []struct {
+		name    string
+		kgen    int
+		wantErr bool
+	}{
+		{name: "current", kgen: 3},
+		{name: "older", kgen: 1, wantErr: true},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			raw, err := Codec.Marshal(&claims.Footer{KeyGeneration: testCase.kgen})
+			assert.NoError(t, err)
+
+			err = claims.NewParser(claims.WithFooterCodec(Codec), claims.WithMinKeyGeneration(3)).ValidateFooter(raw)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, claims.ErrKeyGenerationTooOld)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
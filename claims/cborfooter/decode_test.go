@@ -0,0 +1,64 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package cborfooter
+
+import (
+	"crypto/rand"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"zntr.io/paseto"
+	"zntr.io/paseto/claims"
+	pasetov4 "zntr.io/paseto/v4"
+)
+
+func TestDecodeAndValidate(t *testing.T) {
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	var resolved []string
+	provider := paseto.KeyProviderFunc(func(_ paseto.Header, kid string) (any, error) {
+		resolved = append(resolved, kid)
+		if kid == "k4" {
+			return k4, nil
+		}
+		return nil, errors.New("unknown key")
+	})
+
+	footer, err := Codec.Marshal(&claims.Footer{KeyID: "k4", KeyGeneration: 2})
+	assert.NoError(t, err)
+	token, err := pasetov4.Encrypt(rand.Reader, k4, []byte(`{"sub":"user-1"}`), footer, nil)
+	assert.NoError(t, err)
+
+	// The key identifier is read with the parser codec
+	c, err := paseto.DecodeAndValidate(token, provider, claims.WithFooterCodec(Codec))
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", c.Subject)
+
+	// Footer rules use the same codec
+	_, err = paseto.DecodeAndValidate(token, provider, claims.WithFooterCodec(Codec), claims.WithMinKeyGeneration(3))
+	assert.ErrorIs(t, err, claims.ErrKeyGenerationTooOld)
+
+	// The default JSON codec doesn't find the key identifier
+	_, err = paseto.DecodeAndValidate(token, provider)
+	assert.ErrorContains(t, err, "unable to resolve key")
+
+	assert.Equal(t, []string{"k4", "k4", ""}, resolved)
+}
@@ -0,0 +1,22 @@
+module zntr.io/paseto/claims/cborfooter
+
+go 1.23
+
+require (
+	github.com/fxamacker/cbor/v2 v2.9.2
+	github.com/stretchr/testify v1.10.0
+	zntr.io/paseto v0.0.0-00010101000000-000000000000
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/klauspost/cpuid/v2 v2.0.9 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	github.com/x448/float16 v0.8.4 // indirect
+	golang.org/x/crypto v0.31.0 // indirect
+	golang.org/x/sys v0.28.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+	lukechampine.com/blake3 v1.3.0 // indirect
+)
+
+replace zntr.io/paseto => ../..
@@ -0,0 +1,111 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package claims provides the PASETO registered claims model.
+package claims
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+)
+
+// Claims represents the PASETO payload claims.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/02-Implementation-Guide/04-Claims.md
+type Claims struct {
+	Issuer     string     `json:"iss,omitempty"`
+	Subject    string     `json:"sub,omitempty"`
+	Audience   Audience   `json:"aud,omitempty"`
+	Expiration *time.Time `json:"exp,omitempty"`
+	NotBefore  *time.Time `json:"nbf,omitempty"`
+	IssuedAt   *time.Time `json:"iat,omitempty"`
+	ID         string     `json:"jti,omitempty"`
+
+	// Custom holds all non-registered claims.
+	Custom map[string]any `json:"-"`
+}
+
+// registeredClaims is used to prevent recursive JSON encoding calls.
+type registeredClaims Claims
+
+var registeredClaimNames = map[string]struct{}{
+	"iss": {}, "sub": {}, "aud": {}, "exp": {}, "nbf": {}, "iat": {}, "jti": {},
+}
+
+// IsRegistered returns true if the given claim name is a registered claim.
+func IsRegistered(name string) bool {
+	_, ok := registeredClaimNames[name]
+	return ok
+}
+
+// MarshalJSON encodes registered and custom claims as a single JSON object.
+func (c Claims) MarshalJSON() ([]byte, error) {
+	// Encode registered claims
+	raw, err := json.Marshal(registeredClaims(c))
+	if err != nil {
+		return nil, err
+	}
+	if len(c.Custom) == 0 {
+		return raw, nil
+	}
+
+	// Merge custom claims
+	all := map[string]json.RawMessage{}
+	if err := json.Unmarshal(raw, &all); err != nil {
+		return nil, err
+	}
+	for k, v := range c.Custom {
+		if IsRegistered(k) {
+			return nil, fmt.Errorf("paseto: custom claim %q overrides a registered claim", k)
+		}
+
+		value, err := json.Marshal(v)
+		if err != nil {
+			return nil, fmt.Errorf("paseto: unable to encode custom claim %q: %w", k, err)
+		}
+		all[k] = value
+	}
+
+	// No error
+	return json.Marshal(all)
+}
+
+// UnmarshalJSON decodes registered claims and keeps the others as custom claims.
+func (c *Claims) UnmarshalJSON(data []byte) error {
+	// Decode registered claims
+	var rc registeredClaims
+	if err := json.Unmarshal(data, &rc); err != nil {
+		return err
+	}
+
+	// Decode all claims
+	all := map[string]any{}
+	if err := json.Unmarshal(data, &all); err != nil {
+		return err
+	}
+	for k := range registeredClaimNames {
+		delete(all, k)
+	}
+	if len(all) > 0 {
+		rc.Custom = all
+	}
+
+	*c = Claims(rc)
+
+	// No error
+	return nil
+}
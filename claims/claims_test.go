@@ -0,0 +1,60 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestClaims_JSON(t *testing.T) {
+	exp := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC)
+	c := &Claims{
+		Issuer:     "paseto-issuer",
+		Subject:    "user-123",
+		Expiration: &exp,
+		Custom: map[string]any{
+			"data": "this is a secret message",
+		},
+	}
+
+	raw, err := json.Marshal(c)
+	assert.NoError(t, err)
+	assert.JSONEq(t, `{"iss":"paseto-issuer","sub":"user-123","exp":"2022-01-01T00:00:00Z","data":"this is a secret message"}`, string(raw))
+
+	var out Claims
+	assert.NoError(t, json.Unmarshal(raw, &out))
+	assert.Equal(t, c.Issuer, out.Issuer)
+	assert.Equal(t, c.Subject, out.Subject)
+	assert.True(t, exp.Equal(*out.Expiration))
+	assert.Equal(t, c.Custom, out.Custom)
+}
+
+func TestClaims_JSON_CustomOverride(t *testing.T) {
+	c := &Claims{
+		Custom: map[string]any{
+			"exp": 1234,
+		},
+	}
+
+	_, err := json.Marshal(c)
+	assert.Error(t, err)
+}
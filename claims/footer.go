@@ -0,0 +1,86 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+)
+
+// FooterCodec describes the footer serialization used by high-level helpers.
+// Low-level primitives handle the footer as raw bytes.
+//
+// A CBOR implementation is provided by the zntr.io/paseto/claims/cborfooter
+// module, kept apart so that JSON users don't depend on a CBOR library.
+type FooterCodec interface {
+	Marshal(v any) ([]byte, error)
+	Unmarshal(data []byte, v any) error
+}
+
+// JSONFooterCodec encodes footers as JSON objects (default).
+var JSONFooterCodec FooterCodec = jsonFooterCodec{}
+
+// Footer represents well-known footer claims.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/02-Implementation-Guide/04-Claims.md#optional-footer-claims
+type Footer struct {
+	KeyID      string `json:"kid,omitempty"`
+	WrappedKey string `json:"wpk,omitempty"`
+	// Generator identifies the library which produced the token (non
+	// standard, informative only).
+	Generator string `json:"gen,omitempty"`
+	// KeyGeneration is the generation number of the key which produced the
+	// token (non standard), see WithMinKeyGeneration.
+	KeyGeneration int `json:"kgen,omitempty"`
+}
+
+// ErrKeyGenerationTooOld is raised when the token footer key generation is
+// lower than the required minimum.
+var ErrKeyGenerationTooOld = errors.New("paseto: token key generation is too old")
+
+// WithMinKeyGeneration rejects tokens whose footer `kgen` is lower than n,
+// including tokens without `kgen`. Bumping n invalidates all the tokens
+// minted before a key rotation without maintaining a revocation list.
+//
+// The rule is applied by ParseFooter and ValidateFooter.
+func WithMinKeyGeneration(n int) Rule {
+	return func(p *Parser) {
+		if n < 0 {
+			p.setError(errors.New("paseto: minimum key generation must not be negative"))
+			return
+		}
+		p.footerChecks = append(p.footerChecks, func(f *Footer) error {
+			if f.KeyGeneration < n {
+				return fmt.Errorf("%w: %d < %d", ErrKeyGenerationTooOld, f.KeyGeneration, n)
+			}
+			return nil
+		})
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type jsonFooterCodec struct{}
+
+func (jsonFooterCodec) Marshal(v any) ([]byte, error) {
+	return json.Marshal(v)
+}
+
+func (jsonFooterCodec) Unmarshal(data []byte, v any) error {
+	return json.Unmarshal(data, v)
+}
@@ -0,0 +1,197 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// ErrNonJSONPayload is raised when the token payload is not a JSON object.
+var ErrNonJSONPayload = errors.New("paseto: payload is not a JSON object")
+
+// Parser decodes token payloads and footers as claims.
+type Parser struct {
+	footerCodec FooterCodec
+	clock       Clock
+	// iatSkew is the tolerated `iat` clock skew set by WithRejectFutureIat.
+	iatSkew time.Duration
+	// payloadChecks are applied on the raw payload before decoding.
+	payloadChecks []func(payload []byte) error
+	// checks are applied on the decoded claims.
+	checks []func(c *Claims) error
+	// footerChecks are applied on the decoded footer.
+	footerChecks []func(f *Footer) error
+	err          error
+}
+
+// Rule configures the parser behavior or adds a validation rule.
+type Rule func(*Parser)
+
+// NewParser creates a claims parser with the given rules.
+func NewParser(rules ...Rule) *Parser {
+	p := &Parser{
+		footerCodec: JSONFooterCodec,
+		clock:       SystemClock,
+	}
+	for _, r := range rules {
+		r(p)
+	}
+
+	return p
+}
+
+// WithFooterCodec sets the codec used to decode the footer.
+func WithFooterCodec(codec FooterCodec) Rule {
+	return func(p *Parser) {
+		if codec != nil {
+			p.footerCodec = codec
+		}
+	}
+}
+
+// Parse decodes the given JSON payload as claims and applies the validation
+// rules.
+func (p *Parser) Parse(payload []byte) (*Claims, error) {
+	// Check parser configuration
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	// Apply payload validation rules
+	for _, chk := range p.payloadChecks {
+		if err := chk(payload); err != nil {
+			return nil, err
+		}
+	}
+
+	// Check payload format
+	if !isJSONObject(payload) {
+		return nil, ErrNonJSONPayload
+	}
+
+	var c Claims
+	if err := json.Unmarshal(payload, &c); err != nil {
+		return nil, fmt.Errorf("paseto: unable to decode claims: %w", err)
+	}
+
+	// Apply claims validation rules
+	for _, chk := range p.checks {
+		if err := chk(&c); err != nil {
+			return nil, err
+		}
+	}
+
+	// No error
+	return &c, nil
+}
+
+// isJSONObject returns true when the payload is a valid JSON object.
+func isJSONObject(payload []byte) bool {
+	trimmed := bytes.TrimSpace(payload)
+	return len(trimmed) > 0 && trimmed[0] == '{' && json.Valid(trimmed)
+}
+
+func (p *Parser) setError(err error) {
+	if p.err == nil {
+		p.err = err
+	}
+}
+
+// ParseFooter decodes the given footer using the configured codec.
+func (p *Parser) ParseFooter(footer []byte) (*Footer, error) {
+	// Check parser configuration
+	if p.err != nil {
+		return nil, p.err
+	}
+
+	// Check arguments
+	if len(footer) == 0 {
+		return nil, errors.New("paseto: footer is blank")
+	}
+
+	var f Footer
+	if err := p.footerCodec.Unmarshal(footer, &f); err != nil {
+		return nil, fmt.Errorf("paseto: unable to decode footer: %w", err)
+	}
+
+	// Apply footer validation rules
+	for _, chk := range p.footerChecks {
+		if err := chk(&f); err != nil {
+			return nil, err
+		}
+	}
+
+	// No error
+	return &f, nil
+}
+
+// KeyID returns the `kid` claim of the footer decoded with the configured
+// codec, it is empty when the footer is blank or can't be decoded. The footer
+// rules are not applied, it is meant to resolve the key of a token whose
+// footer is not authenticated yet.
+func (p *Parser) KeyID(footer []byte) string {
+	// Check arguments
+	if p.err != nil || len(footer) == 0 {
+		return ""
+	}
+
+	var f Footer
+	if err := p.footerCodec.Unmarshal(footer, &f); err != nil {
+		return ""
+	}
+
+	return f.KeyID
+}
+
+// ValidateFooter applies the footer validation rules to the given footer. It
+// is a no-op when no footer rule is configured, a blank footer is checked as
+// an empty one.
+//
+// The footer must be authenticated (token decrypted or verified) before being
+// validated.
+func (p *Parser) ValidateFooter(footer []byte) error {
+	// Check parser configuration
+	if p.err != nil {
+		return p.err
+	}
+	if len(p.footerChecks) == 0 {
+		return nil
+	}
+
+	// Decode the footer
+	var f Footer
+	if len(footer) > 0 {
+		if err := p.footerCodec.Unmarshal(footer, &f); err != nil {
+			return fmt.Errorf("paseto: unable to decode footer: %w", err)
+		}
+	}
+
+	// Apply footer validation rules
+	for _, chk := range p.footerChecks {
+		if err := chk(&f); err != nil {
+			return err
+		}
+	}
+
+	// No error
+	return nil
+}
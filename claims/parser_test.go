@@ -0,0 +1,145 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParser_ParseFooter(t *testing.T) {
+	footer := &Footer{KeyID: "zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN"}
+
+	testCases := []struct {
+		name  string
+		codec FooterCodec
+	}{
+		{name: "json", codec: JSONFooterCodec},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			raw, err := testCase.codec.Marshal(footer)
+			assert.NoError(t, err)
+
+			out, err := NewParser(WithFooterCodec(testCase.codec)).ParseFooter(raw)
+			assert.NoError(t, err)
+			assert.Equal(t, footer, out)
+		})
+	}
+}
+
+func TestParser_ParseFooter_Blank(t *testing.T) {
+	_, err := NewParser().ParseFooter(nil)
+	assert.Error(t, err)
+}
+
+func TestWithMinKeyGeneration(t *testing.T) {
+	testCases := []struct {
+		name    string
+		codec   FooterCodec
+		footer  []byte
+		wantErr bool
+	}{
+		{name: "current", codec: JSONFooterCodec, footer: []byte(`{"kid":"k1","kgen":3}`)},
+		{name: "newer", codec: JSONFooterCodec, footer: []byte(`{"kgen":4}`)},
+		{name: "older", codec: JSONFooterCodec, footer: []byte(`{"kid":"k1","kgen":2}`), wantErr: true},
+		{name: "missing", codec: JSONFooterCodec, footer: []byte(`{"kid":"k1"}`), wantErr: true},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			p := NewParser(WithFooterCodec(testCase.codec), WithMinKeyGeneration(3))
+
+			_, err := p.ParseFooter(testCase.footer)
+			errValidate := p.ValidateFooter(testCase.footer)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrKeyGenerationTooOld)
+				assert.ErrorIs(t, errValidate, ErrKeyGenerationTooOld)
+				return
+			}
+			assert.NoError(t, err)
+			assert.NoError(t, errValidate)
+		})
+	}
+
+	// Blank footer is checked as an empty one
+	assert.ErrorIs(t, NewParser(WithMinKeyGeneration(1)).ValidateFooter(nil), ErrKeyGenerationTooOld)
+	assert.NoError(t, NewParser(WithMinKeyGeneration(0)).ValidateFooter(nil))
+
+	// Footer is not decoded without footer rules
+	assert.NoError(t, NewParser().ValidateFooter([]byte("raw-footer")))
+	assert.Error(t, NewParser(WithMinKeyGeneration(1)).ValidateFooter([]byte("raw-footer")))
+
+	// Invalid minimum
+	assert.Error(t, NewParser(WithMinKeyGeneration(-1)).ValidateFooter(nil))
+}
+
+func TestParser_Parse_NonJSONPayload(t *testing.T) {
+	testCases := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{name: "blank", payload: "", wantErr: true},
+		{name: "null", payload: "null", wantErr: true},
+		{name: "string", payload: `"user-123"`, wantErr: true},
+		{name: "array", payload: `[{"sub":"user-123"}]`, wantErr: true},
+		{name: "raw bytes", payload: "not json", wantErr: true},
+		{name: "truncated object", payload: `{"sub":"user-123"`, wantErr: true},
+		{name: "object", payload: `{"sub":"user-123"}`},
+		{name: "object with whitespaces", payload: " \n{\"sub\":\"user-123\"}\n"},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			c, err := NewParser().Parse([]byte(testCase.payload))
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrNonJSONPayload)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "user-123", c.Subject)
+		})
+	}
+}
+
+func TestParser_KeyID(t *testing.T) {
+	testCases := []struct {
+		name   string
+		rules  []Rule
+		footer []byte
+		want   string
+	}{
+		{name: "json", footer: []byte(`{"kid":"k1"}`), want: "k1"},
+		{name: "blank", footer: nil},
+		{name: "raw footer", footer: []byte("raw-footer")},
+		{name: "no kid", footer: []byte(`{"wpk":"k1"}`)},
+		{name: "footer rules are not applied", rules: []Rule{WithMinKeyGeneration(3)}, footer: []byte(`{"kid":"k1","kgen":1}`), want: "k1"},
+		{name: "invalid parser", rules: []Rule{WithMinKeyGeneration(-1)}, footer: []byte(`{"kid":"k1"}`)},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, NewParser(testCase.rules...).KeyID(testCase.footer))
+		})
+	}
+}
@@ -0,0 +1,77 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrMissingClaim is raised when a required claim is absent or empty.
+var ErrMissingClaim = errors.New("paseto: required claim is missing")
+
+// WithRequiredClaims checks that the given claims are present and not empty,
+// whatever their value. Registered and custom claims are supported.
+func WithRequiredClaims(names ...string) Rule {
+	return func(p *Parser) {
+		p.checks = append(p.checks, func(c *Claims) error {
+			for _, name := range names {
+				if !c.has(name) {
+					return fmt.Errorf("%w: %q", ErrMissingClaim, name)
+				}
+			}
+			return nil
+		})
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+// has returns true when the named claim is present and not empty.
+func (c *Claims) has(name string) bool {
+	switch name {
+	case "iss":
+		return c.Issuer != ""
+	case "sub":
+		return c.Subject != ""
+	case "aud":
+		for _, a := range c.Audience {
+			if a != "" {
+				return true
+			}
+		}
+		return false
+	case "exp":
+		return c.Expiration != nil
+	case "nbf":
+		return c.NotBefore != nil
+	case "iat":
+		return c.IssuedAt != nil
+	case "jti":
+		return c.ID != ""
+	default:
+		v, ok := c.Custom[name]
+		if !ok || v == nil {
+			return false
+		}
+		if s, isString := v.(string); isString {
+			return s != ""
+		}
+		return true
+	}
+}
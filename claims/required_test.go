@@ -0,0 +1,65 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithRequiredClaims(t *testing.T) {
+	p := NewParser(WithRequiredClaims("iss", "sub", "aud", "tenant"))
+
+	testCases := []struct {
+		name        string
+		payload     string
+		wantMissing string
+	}{
+		{name: "valid", payload: `{"iss":"auth","sub":"user-1","aud":["api"],"tenant":"acme"}`},
+		{name: "missing iss", payload: `{"sub":"user-1","aud":"api","tenant":"acme"}`, wantMissing: `"iss"`},
+		{name: "empty sub", payload: `{"iss":"auth","sub":"","aud":"api","tenant":"acme"}`, wantMissing: `"sub"`},
+		{name: "empty aud", payload: `{"iss":"auth","sub":"user-1","aud":[],"tenant":"acme"}`, wantMissing: `"aud"`},
+		{name: "blank aud", payload: `{"iss":"auth","sub":"user-1","aud":"","tenant":"acme"}`, wantMissing: `"aud"`},
+		{name: "missing custom", payload: `{"iss":"auth","sub":"user-1","aud":"api"}`, wantMissing: `"tenant"`},
+		{name: "null custom", payload: `{"iss":"auth","sub":"user-1","aud":"api","tenant":null}`, wantMissing: `"tenant"`},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := p.Parse([]byte(testCase.payload))
+			if testCase.wantMissing == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrMissingClaim)
+			assert.ErrorContains(t, err, testCase.wantMissing)
+		})
+	}
+}
+
+func TestWithRequiredClaims_TimeClaims(t *testing.T) {
+	p := NewParser(WithRequiredClaims("exp", "nbf", "iat", "jti"))
+
+	_, err := p.Parse([]byte(`{"exp":"2024-01-01T00:00:00Z","nbf":"2024-01-01T00:00:00Z","iat":"2024-01-01T00:00:00Z","jti":"1"}`))
+	assert.NoError(t, err)
+
+	_, err = p.Parse([]byte(`{"exp":"2024-01-01T00:00:00Z","nbf":"2024-01-01T00:00:00Z","jti":"1"}`))
+	assert.ErrorIs(t, err, ErrMissingClaim)
+	assert.ErrorContains(t, err, `"iat"`)
+}
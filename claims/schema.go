@@ -0,0 +1,264 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+)
+
+// SchemaValidator validates a raw JSON payload against a schema.
+type SchemaValidator interface {
+	Validate(payload []byte) error
+}
+
+// ErrSchemaViolation is raised when the payload doesn't match the schema.
+var ErrSchemaViolation = errors.New("paseto: claims don't match the schema")
+
+// WithSchema validates the payload against the given JSON schema using the
+// bundled minimal validator.
+//
+// The supported keywords are `type`, `properties`, `required`,
+// `additionalProperties` (boolean form), `items` and `enum`; annotations
+// (`$schema`, `$id`, `$comment`, `title`, `description`) are accepted and
+// ignored. Any other keyword is rejected. Use WithSchemaValidator to plug a
+// complete JSON schema implementation.
+func WithSchema(schemaJSON []byte) Rule {
+	return func(p *Parser) {
+		v, err := NewSchemaValidator(schemaJSON)
+		if err != nil {
+			p.setError(err)
+			return
+		}
+		WithSchemaValidator(v)(p)
+	}
+}
+
+// WithSchemaValidator validates the payload using the given schema validator.
+func WithSchemaValidator(v SchemaValidator) Rule {
+	return func(p *Parser) {
+		if v == nil {
+			p.setError(errors.New("paseto: schema validator must not be nil"))
+			return
+		}
+		p.payloadChecks = append(p.payloadChecks, v.Validate)
+	}
+}
+
+// NewSchemaValidator compiles the given JSON schema as a minimal validator.
+//
+// Unsupported keywords are rejected so that a schema can't look stricter than
+// what is actually enforced.
+func NewSchemaValidator(schemaJSON []byte) (SchemaValidator, error) {
+	dec := json.NewDecoder(bytes.NewReader(schemaJSON))
+	dec.UseNumber()
+	dec.DisallowUnknownFields()
+
+	var s schema
+	if err := dec.Decode(&s); err != nil {
+		return nil, fmt.Errorf("paseto: unable to decode JSON schema: %w", err)
+	}
+
+	// No error
+	return &s, nil
+}
+
+// -----------------------------------------------------------------------------
+
+type schema struct {
+	Type                 string             `json:"type,omitempty"`
+	Properties           map[string]*schema `json:"properties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	AdditionalProperties *bool              `json:"additionalProperties,omitempty"`
+	Items                *schema            `json:"items,omitempty"`
+	Enum                 []any              `json:"enum,omitempty"`
+
+	// Annotations, accepted but not enforced.
+	Schema      json.RawMessage `json:"$schema,omitempty"`
+	ID          json.RawMessage `json:"$id,omitempty"`
+	Comment     json.RawMessage `json:"$comment,omitempty"`
+	Title       json.RawMessage `json:"title,omitempty"`
+	Description json.RawMessage `json:"description,omitempty"`
+}
+
+func (s *schema) Validate(payload []byte) error {
+	dec := json.NewDecoder(bytes.NewReader(payload))
+	dec.UseNumber()
+
+	var v any
+	if err := dec.Decode(&v); err != nil {
+		return fmt.Errorf("%w: %v", ErrSchemaViolation, err)
+	}
+
+	if errs := s.validate("", v); len(errs) > 0 {
+		return fmt.Errorf("%w: %s", ErrSchemaViolation, strings.Join(errs, ", "))
+	}
+
+	// No error
+	return nil
+}
+
+func (s *schema) validate(path string, v any) []string {
+	if s == nil {
+		return nil
+	}
+
+	// Check type
+	if s.Type != "" && !matchType(s.Type, v) {
+		return []string{fmt.Sprintf("%s must be of type %s", describe(path), s.Type)}
+	}
+
+	// Check enumeration
+	if len(s.Enum) > 0 && !matchEnum(s.Enum, v) {
+		return []string{fmt.Sprintf("%s has an unexpected value", describe(path))}
+	}
+
+	var errs []string
+	switch value := v.(type) {
+	case map[string]any:
+		for _, name := range s.Required {
+			if _, ok := value[name]; !ok {
+				errs = append(errs, fmt.Sprintf("%s is required", describe(join(path, name))))
+			}
+		}
+
+		// Sort names for stable error messages
+		names := make([]string, 0, len(value))
+		for name := range value {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			prop, ok := s.Properties[name]
+			if !ok {
+				if s.AdditionalProperties != nil && !*s.AdditionalProperties {
+					errs = append(errs, fmt.Sprintf("%s is not allowed", describe(join(path, name))))
+				}
+				continue
+			}
+			errs = append(errs, prop.validate(join(path, name), value[name])...)
+		}
+	case []any:
+		for idx, item := range value {
+			errs = append(errs, s.Items.validate(fmt.Sprintf("%s[%d]", path, idx), item)...)
+		}
+	}
+
+	return errs
+}
+
+func matchType(typ string, v any) bool {
+	switch typ {
+	case "object":
+		_, ok := v.(map[string]any)
+		return ok
+	case "array":
+		_, ok := v.([]any)
+		return ok
+	case "string":
+		_, ok := v.(string)
+		return ok
+	case "boolean":
+		_, ok := v.(bool)
+		return ok
+	case "null":
+		return v == nil
+	case "number":
+		_, ok := v.(json.Number)
+		return ok
+	case "integer":
+		n, ok := v.(json.Number)
+		if !ok {
+			return false
+		}
+		_, err := n.Int64()
+		return err == nil
+	default:
+		return false
+	}
+}
+
+func matchEnum(enum []any, v any) bool {
+	for _, e := range enum {
+		if equalValues(e, v) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// equalValues compares decoded JSON values, numbers being compared by value
+// so that 1, 1.0 and 1e0 are equal.
+func equalValues(a, b any) bool {
+	switch x := a.(type) {
+	case json.Number:
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		rx, okx := new(big.Rat).SetString(x.String())
+		ry, oky := new(big.Rat).SetString(y.String())
+		return okx && oky && rx.Cmp(ry) == 0
+	case map[string]any:
+		y, ok := b.(map[string]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, xv := range x {
+			yv, ok := y[k]
+			if !ok || !equalValues(xv, yv) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		y, ok := b.([]any)
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for idx := range x {
+			if !equalValues(x[idx], y[idx]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return a == b
+	}
+}
+
+func join(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func describe(path string) string {
+	if path == "" {
+		return "payload"
+	}
+	return fmt.Sprintf("claim %q", path)
+}
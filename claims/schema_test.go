@@ -0,0 +1,90 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithSchema(t *testing.T) {
+	schema := []byte(`{
+		"type": "object",
+		"required": ["sub", "scope"],
+		"additionalProperties": false,
+		"properties": {
+			"sub": {"type": "string"},
+			"exp": {"type": "string"},
+			"scope": {"type": "array", "items": {"type": "string", "enum": ["read", "write"]}},
+			"level": {"type": "integer"},
+			"ver": {"enum": [1, "latest"]}
+		}
+	}`)
+
+	testCases := []struct {
+		name    string
+		payload string
+		wantErr string
+	}{
+		{name: "valid", payload: `{"sub":"user-123","scope":["read"],"level":2}`},
+		{name: "missing", payload: `{"sub":"user-123"}`, wantErr: `claim "scope" is required`},
+		{name: "extra", payload: `{"sub":"user-123","scope":[],"admin":true}`, wantErr: `claim "admin" is not allowed`},
+		{name: "wrong type", payload: `{"sub":123,"scope":[]}`, wantErr: `claim "sub" must be of type string`},
+		{name: "wrong integer", payload: `{"sub":"user-123","scope":[],"level":1.5}`, wantErr: `claim "level" must be of type integer`},
+		{name: "wrong item", payload: `{"sub":"user-123","scope":["admin"]}`, wantErr: `claim "scope[0]" has an unexpected value`},
+		{name: "enum number", payload: `{"sub":"user-123","scope":[],"ver":1.0}`},
+		{name: "enum exponent", payload: `{"sub":"user-123","scope":[],"ver":1e0}`},
+		{name: "enum string", payload: `{"sub":"user-123","scope":[],"ver":"latest"}`},
+		{name: "wrong enum number", payload: `{"sub":"user-123","scope":[],"ver":1.5}`, wantErr: `claim "ver" has an unexpected value`},
+		{name: "not an object", payload: `"user-123"`, wantErr: `payload must be of type object`},
+	}
+
+	p := NewParser(WithSchema(schema))
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := p.Parse([]byte(testCase.payload))
+			if testCase.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, ErrSchemaViolation)
+			assert.ErrorContains(t, err, testCase.wantErr)
+		})
+	}
+}
+
+func TestWithSchema_Invalid(t *testing.T) {
+	_, err := NewParser(WithSchema([]byte(`{`))).Parse([]byte(`{}`))
+	assert.Error(t, err)
+
+	_, err = NewParser(WithSchemaValidator(nil)).Parse([]byte(`{}`))
+	assert.Error(t, err)
+}
+
+func TestNewSchemaValidator_UnsupportedKeyword(t *testing.T) {
+	_, err := NewSchemaValidator([]byte(`{"type":"object","properties":{"sub":{"type":"string","pattern":"^user-"}}}`))
+	assert.ErrorContains(t, err, `unknown field "pattern"`)
+
+	_, err = NewSchemaValidator([]byte(`{"type":"string","minLength":3}`))
+	assert.Error(t, err)
+
+	_, err = NewSchemaValidator([]byte(`{"$schema":"https://json-schema.org/draft/2020-12/schema","title":"token","type":"object"}`))
+	assert.NoError(t, err)
+}
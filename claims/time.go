@@ -0,0 +1,121 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"errors"
+	"time"
+)
+
+// Clock provides the current time to the time-based validation rules.
+type Clock interface {
+	Now() time.Time
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() time.Time
+
+// Now returns the time returned by the function.
+func (f ClockFunc) Now() time.Time {
+	return f()
+}
+
+// SystemClock is the default clock, it returns the system time.
+var SystemClock Clock = ClockFunc(time.Now)
+
+var (
+	// ErrTokenExpired is raised when the `exp` claim is in the past.
+	ErrTokenExpired = errors.New("paseto: token is expired")
+	// ErrTokenNotYetValid is raised when the `nbf` claim is in the future.
+	ErrTokenNotYetValid = errors.New("paseto: token is not valid yet")
+	// ErrTokenIssuedInFuture is raised when the `iat` claim is in the future.
+	ErrTokenIssuedInFuture = errors.New("paseto: token is issued in the future")
+)
+
+// WithClock sets the clock used by the time-based validation rules. The rules
+// read the clock when the payload is parsed, so the rule order doesn't matter.
+func WithClock(c Clock) Rule {
+	return func(p *Parser) {
+		if c == nil {
+			p.setError(errors.New("paseto: clock must not be nil"))
+			return
+		}
+		p.clock = c
+	}
+}
+
+// WithTimeValidation checks the `exp`, `nbf` and `iat` claims when they are
+// present. A token is rejected when it is expired (now is at or after `exp`),
+// not valid yet (now is before `nbf`) or issued in the future (now is before
+// `iat`). The `iat` check tolerates the skew set with WithRejectFutureIat,
+// whatever the rule order.
+func WithTimeValidation() Rule {
+	return func(p *Parser) {
+		p.checks = append(p.checks, func(c *Claims) error {
+			return checkTime(c, p.clock.Now(), p.iatSkew)
+		})
+	}
+}
+
+// WithRejectFutureIat rejects the tokens whose `iat` claim is more than skew
+// in the future with ErrTokenIssuedInFuture, regardless of `nbf`. A missing
+// `iat` claim is not checked, combine with WithRequiredClaims("iat") to
+// require it. The skew also applies to the `iat` check of WithTimeValidation.
+func WithRejectFutureIat(skew time.Duration) Rule {
+	return func(p *Parser) {
+		if skew < 0 {
+			p.setError(errors.New("paseto: iat skew must not be negative"))
+			return
+		}
+		p.iatSkew = skew
+		p.checks = append(p.checks, func(c *Claims) error {
+			if c.IssuedAt != nil && c.IssuedAt.After(p.clock.Now().Add(skew)) {
+				return ErrTokenIssuedInFuture
+			}
+			return nil
+		})
+	}
+}
+
+// Valid runs the default temporal checks of WithTimeValidation against the
+// SystemClock. It allows the claims to be used with the validation frameworks
+// expecting a `Valid() error` method (jwt-go convention).
+//
+// No clock skew is tolerated: the token is rejected as soon as now is at or
+// after `exp`, or before `nbf` or `iat`. Use a Parser with WithClock to
+// validate against a shifted clock.
+func (c Claims) Valid() error {
+	return checkTime(&c, SystemClock.Now(), 0)
+}
+
+// -----------------------------------------------------------------------------
+
+func checkTime(c *Claims, now time.Time, iatSkew time.Duration) error {
+	if c.Expiration != nil && !now.Before(*c.Expiration) {
+		return ErrTokenExpired
+	}
+	if c.NotBefore != nil && now.Before(*c.NotBefore) {
+		return ErrTokenNotYetValid
+	}
+	if c.IssuedAt != nil && c.IssuedAt.After(now.Add(iatSkew)) {
+		return ErrTokenIssuedInFuture
+	}
+
+	// No error
+	return nil
+}
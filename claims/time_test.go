@@ -0,0 +1,158 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package claims
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWithTimeValidation(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	payload := []byte(`{"iat":"2024-01-01T11:00:00Z","nbf":"2024-01-01T11:30:00Z","exp":"2024-01-01T13:00:00Z"}`)
+
+	testCases := []struct {
+		name    string
+		now     time.Time
+		wantErr error
+	}{
+		{name: "valid", now: now},
+		{name: "at nbf", now: now.Add(-30 * time.Minute)},
+		{name: "just before exp", now: now.Add(time.Hour - time.Nanosecond)},
+		{name: "at exp", now: now.Add(time.Hour), wantErr: ErrTokenExpired},
+		{name: "after exp", now: now.Add(2 * time.Hour), wantErr: ErrTokenExpired},
+		{name: "before nbf", now: now.Add(-31 * time.Minute), wantErr: ErrTokenNotYetValid},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			p := NewParser(
+				WithTimeValidation(),
+				WithClock(ClockFunc(func() time.Time { return testCase.now })),
+			)
+			_, err := p.Parse(payload)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWithTimeValidation_IssuedInFuture(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	p := NewParser(WithClock(ClockFunc(func() time.Time { return now })), WithTimeValidation())
+
+	_, err := p.Parse([]byte(`{"iat":"2024-01-01T12:00:01Z"}`))
+	assert.ErrorIs(t, err, ErrTokenIssuedInFuture)
+
+	// Missing claims are not checked
+	_, err = p.Parse([]byte(`{"sub":"user-123"}`))
+	assert.NoError(t, err)
+}
+
+func TestWithRejectFutureIat(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	p := NewParser(WithClock(ClockFunc(func() time.Time { return now })), WithRejectFutureIat(5*time.Second))
+
+	testCases := []struct {
+		name    string
+		payload string
+		wantErr bool
+	}{
+		{name: "past", payload: `{"iat":"2024-01-01T11:00:00Z"}`},
+		{name: "within skew", payload: `{"iat":"2024-01-01T12:00:05Z"}`},
+		{name: "beyond skew", payload: `{"iat":"2024-01-01T12:00:06Z"}`, wantErr: true},
+		{name: "beyond skew with past nbf", payload: `{"iat":"2024-01-01T13:00:00Z","nbf":"2024-01-01T11:00:00Z"}`, wantErr: true},
+		{name: "missing", payload: `{"sub":"user-123"}`},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := p.Parse([]byte(testCase.payload))
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrTokenIssuedInFuture)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+
+	// Negative skew
+	_, err := NewParser(WithRejectFutureIat(-time.Second)).Parse([]byte(`{}`))
+	assert.Error(t, err)
+}
+
+func TestWithRejectFutureIat_WithTimeValidation(t *testing.T) {
+	now := time.Date(2024, time.January, 1, 12, 0, 0, 0, time.UTC)
+	clock := WithClock(ClockFunc(func() time.Time { return now }))
+
+	// The skew applies whatever the rule order
+	for _, p := range []*Parser{
+		NewParser(clock, WithTimeValidation(), WithRejectFutureIat(5*time.Second)),
+		NewParser(clock, WithRejectFutureIat(5*time.Second), WithTimeValidation()),
+	} {
+		_, err := p.Parse([]byte(`{"iat":"2024-01-01T12:00:05Z","exp":"2024-01-01T13:00:00Z"}`))
+		assert.NoError(t, err)
+		_, err = p.Parse([]byte(`{"iat":"2024-01-01T12:00:06Z","exp":"2024-01-01T13:00:00Z"}`))
+		assert.ErrorIs(t, err, ErrTokenIssuedInFuture)
+		_, err = p.Parse([]byte(`{"iat":"2024-01-01T12:00:05Z","exp":"2024-01-01T12:00:00Z"}`))
+		assert.ErrorIs(t, err, ErrTokenExpired)
+	}
+}
+
+func TestWithClock_Nil(t *testing.T) {
+	_, err := NewParser(WithClock(nil)).Parse([]byte(`{}`))
+	assert.Error(t, err)
+}
+
+func TestClaims_Valid(t *testing.T) {
+	past := time.Now().Add(-time.Hour)
+	future := time.Now().Add(time.Hour)
+
+	testCases := []struct {
+		name    string
+		claims  Claims
+		wantErr error
+	}{
+		{name: "no temporal claims", claims: Claims{Subject: "user-123"}},
+		{name: "valid", claims: Claims{IssuedAt: &past, NotBefore: &past, Expiration: &future}},
+		{name: "expired", claims: Claims{Expiration: &past}, wantErr: ErrTokenExpired},
+		{name: "not valid yet", claims: Claims{NotBefore: &future}, wantErr: ErrTokenNotYetValid},
+		{name: "issued in the future", claims: Claims{IssuedAt: &future}, wantErr: ErrTokenIssuedInFuture},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			// Usable through the jwt-go style interface
+			var v interface{ Valid() error } = &testCase.claims
+			err := v.Valid()
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+		})
+	}
+}
@@ -0,0 +1,72 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+)
+
+// SameToken reports whether the given tokens have the same header and the same
+// body, compared in constant time.
+//
+// The footer is ignored so that a token carrying its footer and the same token
+// with a detached footer are considered equal. It is a serialized token
+// comparison only, two encryptions of the same message produce different
+// tokens because of the random nonce.
+func SameToken(a, b string) (bool, error) {
+	// Decode both tokens
+	ha, bodyA, err := tokenBody(a)
+	if err != nil {
+		return false, err
+	}
+	hb, bodyB, err := tokenBody(b)
+	if err != nil {
+		return false, err
+	}
+
+	// Compare headers (public information)
+	if ha.Prefix != hb.Prefix {
+		return false, nil
+	}
+
+	// No error
+	return subtle.ConstantTimeCompare(bodyA, bodyB) == 1, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// tokenBody returns the header and the decoded body of the given token.
+func tokenBody(token string) (Header, []byte, error) {
+	// Check token structure
+	prefix, rawBody, _, err := Split(token)
+	if err != nil {
+		return Header{}, nil, err
+	}
+	h, _ := LookupHeader(prefix)
+
+	// Decode body
+	body, err := base64.RawURLEncoding.DecodeString(rawBody)
+	if err != nil {
+		return Header{}, nil, fmt.Errorf("%w body: %v", ErrInvalidToken, err)
+	}
+
+	// No error
+	return h, body, nil
+}
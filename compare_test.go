@@ -0,0 +1,57 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSameToken(t *testing.T) {
+	testCases := []struct {
+		name    string
+		a, b    string
+		want    bool
+		wantErr bool
+	}{
+		{name: "identical", a: "v4.local.AAAA", b: "v4.local.AAAA", want: true},
+		{name: "detached footer", a: "v4.public.AAAA.BBBB", b: "v4.public.AAAA", want: true},
+		{name: "different footers", a: "v4.public.AAAA.BBBB", b: "v4.public.AAAA.CCCC", want: true},
+		{name: "surrounding whitespaces", a: " v4.local.AAAA\n", b: "v4.local.AAAA", want: true},
+		{name: "different bodies", a: "v4.local.AAAA", b: "v4.local.AAAB"},
+		{name: "different lengths", a: "v4.local.AAAA", b: "v4.local.AAAAAA"},
+		{name: "different headers", a: "v4.local.AAAA", b: "v3.local.AAAA"},
+		{name: "invalid first", a: "v2.local.AAAA", b: "v4.local.AAAA", wantErr: true},
+		{name: "invalid second", a: "v4.local.AAAA", b: "v4.local.", wantErr: true},
+		{name: "invalid encoding", a: "v4.local.A", b: "v4.local.A", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := SameToken(testCase.a, testCase.b)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+}
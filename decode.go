@@ -0,0 +1,206 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"zntr.io/paseto/claims"
+	"zntr.io/paseto/internal/common"
+	"zntr.io/paseto/observer"
+	pasetov3 "zntr.io/paseto/v3"
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+// ErrUnexpectedKeyType is raised when the resolved key type doesn't match the
+// token version and purpose.
+var ErrUnexpectedKeyType = errors.New("paseto: unexpected key type")
+
+// KeyProvider resolves the key used to open a token.
+//
+// The expected key types are *v3.LocalKey and *ecdsa.PublicKey for v3,
+// *v4.LocalKey and ed25519.PublicKey for v4, *v4x.LocalKey and
+// ed25519.PublicKey for v4x.
+type KeyProvider interface {
+	// ResolveKey returns the key for the given token header and footer key
+	// identifier. The key identifier is empty when the token footer doesn't
+	// have one, it is read before the token is authenticated.
+	ResolveKey(h Header, kid string) (any, error)
+}
+
+// KeyProviderFunc adapts a function to the KeyProvider interface.
+type KeyProviderFunc func(h Header, kid string) (any, error)
+
+// ResolveKey calls the function.
+func (f KeyProviderFunc) ResolveKey(h Header, kid string) (any, error) {
+	return f(h, kid)
+}
+
+// DecodeOptions customizes DecodeAndValidateWithOptions.
+type DecodeOptions struct {
+	// Observer is notified of each token opening: observer.OpDecrypt for
+	// local tokens, observer.OpVerify for public tokens, with the token
+	// version (empty when the token is malformed), the error and the duration.
+	Observer observer.Observer
+}
+
+// DecodeAndValidate opens a token of any supported version and purpose with
+// the key resolved by the provider from the footer `kid`, then parses the
+// payload as claims with the given rules. The footer is decoded with the codec
+// set by claims.WithFooterCodec (JSON by default). The footer rules (such as
+// claims.WithMinKeyGeneration) are applied to the authenticated footer. The
+// footer size is checked against the version MaxFooterLength before it is
+// decoded, and the decrypted payload is wiped once the claims are decoded.
+//
+// The token footer, if any, is authenticated with the token but not checked
+// against an expected value, and no implicit assertion is used. Use the
+// version packages directly for these cases.
+func DecodeAndValidate(token string, keys KeyProvider, rules ...claims.Rule) (*claims.Claims, error) {
+	return decodeAndValidate(token, keys, rules)
+}
+
+// DecodeAndValidateWithOptions decodes and validates the token like
+// DecodeAndValidate with the given options.
+func DecodeAndValidateWithOptions(token string, keys KeyProvider, opts DecodeOptions, rules ...claims.Rule) (*claims.Claims, error) {
+	if opts.Observer == nil {
+		return decodeAndValidate(token, keys, rules)
+	}
+
+	// Report the outcome to the observer
+	start := time.Now()
+	c, err := decodeAndValidate(token, keys, rules)
+	op, version := observedOp(token)
+	opts.Observer.ObserveOp(op, version, err, time.Since(start))
+
+	return c, err
+}
+
+// -----------------------------------------------------------------------------
+
+func decodeAndValidate(token string, keys KeyProvider, rules []claims.Rule) (*claims.Claims, error) {
+	// Check arguments
+	if keys == nil {
+		return nil, errors.New("paseto: key provider is nil")
+	}
+
+	// Check token structure
+	prefix, _, rawFooter, err := Split(token)
+	if err != nil {
+		return nil, err
+	}
+	h, _ := LookupHeader(prefix)
+
+	// Split validated the token once trimmed
+	token = strings.TrimSpace(token)
+
+	// Check footer size before decoding
+	if err := common.CheckFooterLength([]byte(rawFooter), maxFooterLength(h)); err != nil {
+		return nil, err
+	}
+
+	// Read the footer
+	footer, err := base64.RawURLEncoding.DecodeString(rawFooter)
+	if err != nil {
+		return nil, fmt.Errorf("%w, footer has invalid encoding: %v", ErrInvalidToken, err)
+	}
+
+	// Resolve the key with the footer codec of the parser (a footer which
+	// can't be decoded has no key identifier)
+	p := claims.NewParser(rules...)
+	key, err := keys.ResolveKey(h, p.KeyID(footer))
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to resolve key: %w", err)
+	}
+
+	// Open the token
+	payload, err := open(h, token, key, footer)
+	if err != nil {
+		return nil, err
+	}
+
+	// Scrub the plaintext once decoded
+	defer Wipe(payload)
+
+	// Apply the footer rules on the authenticated footer
+	if err := p.ValidateFooter(footer); err != nil {
+		return nil, err
+	}
+
+	// No error
+	return p.Parse(payload)
+}
+
+func open(h Header, token string, key any, f []byte) ([]byte, error) {
+	switch h.Prefix {
+	case pasetov3.LocalPrefix:
+		if k, ok := key.(*pasetov3.LocalKey); ok {
+			return pasetov3.Decrypt(k, token, f, nil)
+		}
+	case pasetov3.PublicPrefix:
+		if k, ok := key.(*ecdsa.PublicKey); ok {
+			return pasetov3.Verify(token, k, f, nil)
+		}
+	case pasetov4.LocalPrefix:
+		if k, ok := key.(*pasetov4.LocalKey); ok {
+			return pasetov4.Decrypt(k, token, f, nil)
+		}
+	case pasetov4.PublicPrefix:
+		if k, ok := key.(ed25519.PublicKey); ok {
+			return pasetov4.Verify(token, k, f, nil)
+		}
+	case pasetov4x.LocalPrefix:
+		if k, ok := key.(*pasetov4x.LocalKey); ok {
+			return pasetov4x.Decrypt(k, token, f, nil)
+		}
+	case pasetov4x.PublicPrefix:
+		if k, ok := key.(ed25519.PublicKey); ok {
+			return pasetov4x.Verify(token, k, f, nil)
+		}
+	}
+
+	return nil, fmt.Errorf("%w %T for %q tokens", ErrUnexpectedKeyType, key, h.Prefix)
+}
+
+// maxFooterLength returns the MaxFooterLength setting of the token version.
+func maxFooterLength(h Header) int {
+	switch h.Version {
+	case "v3":
+		return pasetov3.MaxFooterLength
+	case "v4x":
+		return pasetov4x.MaxFooterLength
+	default:
+		return pasetov4.MaxFooterLength
+	}
+}
+
+// observedOp returns the operation and the version reported for the token.
+func observedOp(token string) (op, version string) {
+	h, _ := HeaderOf(strings.TrimSpace(token))
+	if h.Purpose == PurposePublic {
+		return observer.OpVerify, h.Version
+	}
+
+	return observer.OpDecrypt, h.Version
+}
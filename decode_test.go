@@ -0,0 +1,218 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"zntr.io/paseto/claims"
+	"zntr.io/paseto/observer"
+	pasetov3 "zntr.io/paseto/v3"
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+func TestDecodeAndValidate(t *testing.T) {
+	k3, err := pasetov3.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k4x, err := pasetov4x.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	pk4, sk4, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	sk3, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	keys := map[string]any{
+		"k3":       k3,
+		"p3":       &sk3.PublicKey,
+		"k4":       k4,
+		"p4":       pk4,
+		"k4x":      k4x,
+		"p4x":      pk4,
+		"mismatch": k3,
+	}
+	provider := KeyProviderFunc(func(_ Header, kid string) (any, error) {
+		if k, ok := keys[kid]; ok {
+			return k, nil
+		}
+		return nil, errors.New("unknown key")
+	})
+
+	m := []byte(`{"sub":"user-1","aud":"api","exp":"2030-01-01T00:00:00Z"}`)
+	footer := func(kid string) []byte { return []byte(`{"kid":"` + kid + `"}`) }
+	must := func(token string, err error) string {
+		assert.NoError(t, err)
+		return token
+	}
+
+	testCases := []struct {
+		name    string
+		token   string
+		wantErr error
+	}{
+		{name: "v3.local", token: must(pasetov3.Encrypt(rand.Reader, k3, m, footer("k3"), nil))},
+		{name: "v3.public", token: must(pasetov3.Sign(m, sk3, footer("p3"), nil))},
+		{name: "v4.local", token: must(pasetov4.Encrypt(rand.Reader, k4, m, footer("k4"), nil))},
+		{name: "v4.public", token: must(pasetov4.Sign(m, sk4, footer("p4"), nil))},
+		{name: "v4x.local", token: must(pasetov4x.Encrypt(rand.Reader, k4x, m, footer("k4x"), nil))},
+		{name: "v4x.public", token: must(pasetov4x.Sign(m, sk4, footer("p4x"), nil))},
+		{name: "wrong key", token: must(pasetov4.Encrypt(rand.Reader, k4, m, footer("k4x"), nil)), wantErr: ErrUnexpectedKeyType},
+		{name: "key type mismatch", token: must(pasetov4.Encrypt(rand.Reader, k4, m, footer("mismatch"), nil)), wantErr: ErrUnexpectedKeyType},
+		{name: "cross version key", token: must(pasetov3.Encrypt(rand.Reader, k3, m, footer("k4"), nil)), wantErr: ErrUnexpectedKeyType},
+		{name: "invalid token", token: "v2.local.AAAA", wantErr: ErrInvalidToken},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			c, err := DecodeAndValidate(testCase.token, provider, claims.WithAudience("api"))
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, "user-1", c.Subject)
+		})
+	}
+
+	// Unknown key identifier
+	_, err = DecodeAndValidate(must(pasetov4.Encrypt(rand.Reader, k4, m, footer("unknown"), nil)), provider)
+	assert.ErrorContains(t, err, "unable to resolve key")
+
+	// Authentication failure
+	other, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	keys["k4"] = other
+	_, err = DecodeAndValidate(must(pasetov4.Encrypt(rand.Reader, k4, m, footer("k4"), nil)), provider)
+	assert.ErrorIs(t, err, pasetov4.ErrInvalidMAC)
+
+	// Rules are applied
+	keys["k4"] = k4
+	now := time.Date(2031, time.January, 1, 0, 0, 0, 0, time.UTC)
+	_, err = DecodeAndValidate(must(pasetov4.Encrypt(rand.Reader, k4, m, footer("k4"), nil)), provider,
+		claims.WithClock(claims.ClockFunc(func() time.Time { return now })),
+		claims.WithTimeValidation(),
+	)
+	assert.ErrorIs(t, err, claims.ErrTokenExpired)
+
+	// Footer rules are applied
+	_, err = DecodeAndValidate(must(pasetov4.Encrypt(rand.Reader, k4, m, []byte(`{"kid":"k4","kgen":2}`), nil)), provider,
+		claims.WithMinKeyGeneration(3),
+	)
+	assert.ErrorIs(t, err, claims.ErrKeyGenerationTooOld)
+	_, err = DecodeAndValidate(must(pasetov4.Encrypt(rand.Reader, k4, m, []byte(`{"kid":"k4","kgen":3}`), nil)), provider,
+		claims.WithMinKeyGeneration(3),
+	)
+	assert.NoError(t, err)
+
+	_, err = DecodeAndValidate("v4.local.AAAA", nil)
+	assert.Error(t, err)
+}
+
+func TestDecodeAndValidate_FooterTooLarge(t *testing.T) {
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	resolved := false
+	provider := KeyProviderFunc(func(_ Header, _ string) (any, error) {
+		resolved = true
+		return k4, nil
+	})
+
+	footer := []byte(`{"kid":"` + strings.Repeat("a", pasetov4.MaxFooterLength) + `"}`)
+	token, err := pasetov4.Encrypt(rand.Reader, k4, []byte(`{"sub":"user-1"}`), footer, nil)
+	assert.NoError(t, err)
+
+	// The footer is rejected before being decoded
+	_, err = DecodeAndValidate(token, provider)
+	assert.ErrorIs(t, err, pasetov4.ErrFooterTooLarge)
+	assert.False(t, resolved)
+}
+
+func TestDecodeAndValidateWithOptions_Observer(t *testing.T) {
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	sk3, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	type observation struct {
+		op, version string
+		err         error
+		dur         time.Duration
+	}
+	var got []observation
+	o := observer.Func(func(op, version string, err error, dur time.Duration) {
+		got = append(got, observation{op: op, version: version, err: err, dur: dur})
+	})
+
+	provider := KeyProviderFunc(func(h Header, _ string) (any, error) {
+		if h.Version == "v3" {
+			return &sk3.PublicKey, nil
+		}
+		return k4, nil
+	})
+	opts := DecodeOptions{Observer: o}
+
+	m := []byte(`{"sub":"user-1"}`)
+	local, err := pasetov4.Encrypt(rand.Reader, k4, m, nil, nil)
+	assert.NoError(t, err)
+	public, err := pasetov3.Sign(m, sk3, nil, nil)
+	assert.NoError(t, err)
+
+	_, err = DecodeAndValidateWithOptions(local, provider, opts)
+	assert.NoError(t, err)
+	_, err = DecodeAndValidateWithOptions(public, provider, opts)
+	assert.NoError(t, err)
+	_, err = DecodeAndValidateWithOptions("v2.local.AAAA", provider, opts)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = DecodeAndValidateWithOptions(public, WithAllowedVersions(provider, "v4"), opts)
+	assert.ErrorIs(t, err, ErrDisallowedVersion)
+
+	assert.Len(t, got, 4)
+	assert.Equal(t, observation{op: observer.OpDecrypt, version: "v4", dur: got[0].dur}, got[0])
+	assert.Equal(t, observation{op: observer.OpVerify, version: "v3", dur: got[1].dur}, got[1])
+	assert.Equal(t, observer.OpDecrypt, got[2].op)
+	assert.Empty(t, got[2].version)
+	assert.ErrorIs(t, got[2].err, ErrInvalidToken)
+	assert.Equal(t, observer.OpVerify, got[3].op)
+	assert.Equal(t, "v3", got[3].version)
+	assert.ErrorIs(t, got[3].err, ErrDisallowedVersion)
+	for _, obs := range got {
+		assert.Greater(t, obs.dur, time.Duration(0))
+	}
+
+	// A nil provider is reported as an error
+	_, err = DecodeAndValidateWithOptions(local, nil, opts)
+	assert.Error(t, err)
+	assert.Len(t, got, 5)
+
+	// No observer
+	_, err = DecodeAndValidateWithOptions(local, provider, DecodeOptions{})
+	assert.NoError(t, err)
+	assert.Len(t, got, 5)
+}
@@ -0,0 +1,52 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"io"
+	"sync/atomic"
+)
+
+// CountingReader wraps a random source and counts the consumed bytes.
+//
+// It is intended to budget the entropy consumption when the random source is
+// metered (hardware RNG). GenerateLocalKey reads exactly KeyLength bytes, the
+// local Encrypt functions read exactly 32 bytes per token and the v4
+// SequentialEncryptor reads 24 bytes per token (the rest of the nonce is a
+// counter).
+type CountingReader struct {
+	r io.Reader
+	n atomic.Int64
+}
+
+// NewCountingReader wraps the given reader.
+func NewCountingReader(r io.Reader) *CountingReader {
+	return &CountingReader{r: r}
+}
+
+// Read reads from the wrapped reader and counts the returned bytes.
+func (cr *CountingReader) Read(p []byte) (int, error) {
+	n, err := cr.r.Read(p)
+	cr.n.Add(int64(n))
+	return n, err
+}
+
+// Count returns the number of bytes read so far.
+func (cr *CountingReader) Count() int64 {
+	return cr.n.Load()
+}
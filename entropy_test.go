@@ -0,0 +1,67 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pasetov3 "zntr.io/paseto/v3"
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+func TestEntropyConsumption(t *testing.T) {
+	m := []byte("payload")
+	f := []byte("{\"kid\":\"1234\"}")
+
+	t.Run("v3", func(t *testing.T) {
+		r := NewCountingReader(rand.Reader)
+		key, err := pasetov3.GenerateLocalKey(r)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(pasetov3.KeyLength), r.Count())
+
+		_, err = pasetov3.Encrypt(r, key, m, f, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(pasetov3.KeyLength+32), r.Count())
+	})
+
+	t.Run("v4", func(t *testing.T) {
+		r := NewCountingReader(rand.Reader)
+		key, err := pasetov4.GenerateLocalKey(r)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(pasetov4.KeyLength), r.Count())
+
+		_, err = pasetov4.Encrypt(r, key, m, f, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(pasetov4.KeyLength+32), r.Count())
+	})
+
+	t.Run("v4x", func(t *testing.T) {
+		r := NewCountingReader(rand.Reader)
+		key, err := pasetov4x.GenerateLocalKey(r)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(pasetov4x.KeyLength), r.Count())
+
+		_, err = pasetov4x.Encrypt(r, key, m, f, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, int64(pasetov4x.KeyLength+32), r.Count())
+	})
+}
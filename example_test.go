@@ -25,7 +25,7 @@ import (
 	pasetov4 "zntr.io/paseto/v4"
 )
 
-func ExamplePasetoV4LocalWithoutFooter() {
+func Example_pasetoV4LocalWithoutFooter() {
 	// Use this a random source, it must be replaced by rand.Reader for production use.
 	deterministicSeedForTest := bytes.NewReader([]byte("deterministic-random-source-for-tests-1234567890123456789012345678901234567890"))
 
@@ -48,7 +48,7 @@ func ExamplePasetoV4LocalWithoutFooter() {
 	// Output: v4.local.dGVzdHMtMTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTZ-qF7cj1LApZxpU5R2qdaX9Ox9NaKxnXOFQ0MyihHkhiIIv3VicidcEd6u0WjXiG1TouukHAG-
 }
 
-func ExamplePasetoV4LocalWithFooter() {
+func Example_pasetoV4LocalWithFooter() {
 	// Use this a random source, it must be replaced by rand.Reader for production use.
 	deterministicSeedForTest := bytes.NewReader([]byte("deterministic-random-source-for-tests-1234567890123456789012345678901234567890"))
 
@@ -75,7 +75,7 @@ func ExamplePasetoV4LocalWithFooter() {
 	// Output: v4.local.dGVzdHMtMTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTZ-qF7cj1LApZxpU5R2qdaX9Ox9NaKxnXjceRO_8DgJ7yODdxRd6Z0X2rG_InQPO_h6drwJoRKL.eyJraWQiOiIxMjM0NTY3ODkwIn0
 }
 
-func ExamplePasetoV4LocalWithFooterAndImplicitAssertions() {
+func Example_pasetoV4LocalWithFooterAndImplicitAssertions() {
 	// Use this a random source, it must be replaced by rand.Reader for production use.
 	deterministicSeedForTest := bytes.NewReader([]byte("deterministic-random-source-for-tests-1234567890123456789012345678901234567890"))
 
@@ -103,7 +103,7 @@ func ExamplePasetoV4LocalWithFooterAndImplicitAssertions() {
 	// Output: v4.local.dGVzdHMtMTIzNDU2Nzg5MDEyMzQ1Njc4OTAxMjM0NTZ-qF7cj1LApZxpU5R2qdaX9Ox9NaKxnci6ObPVawSbAlqcRdmSDrklvbUqNGk61-tuOKJ0vkFQ.eyJraWQiOiIxMjM0NTY3ODkwIn0
 }
 
-func ExamplePasetoV4LocalDecrypt() {
+func Example_pasetoV4LocalDecrypt() {
 	// Use this a random source, it must be replaced by rand.Reader for production use.
 	deterministicSeedForTest := bytes.NewReader([]byte("deterministic-random-source-for-tests-1234567890123456789012345678901234567890"))
 
@@ -133,7 +133,7 @@ func ExamplePasetoV4LocalDecrypt() {
 }
 
 // -----------------------------------------------------------------------------
-func ExamplePasetoV4PublicSign() {
+func Example_pasetoV4PublicSign() {
 	// Use this a random source, it must be replaced by rand.Reader for production use.
 	deterministicSeedForTest := bytes.NewReader([]byte("deterministic-random-source-for-tests-1234567890123456789012345678901234567890"))
 
@@ -158,7 +158,7 @@ func ExamplePasetoV4PublicSign() {
 	// Output: v4.public.bXkgc3VwZXIgc2VjcmV0IG1lc3NhZ2UbOO-zu6XQbbhmDj0IUEjrmLS_TK1vM69D3pmdbUJmSa7A4c0qjEi9q-DQiMD6UUtbGEMXA1z9zdRskpGfStQH.eyJraWQiOiIxMjM0NTY3ODkwIn0
 }
 
-func ExamplePasetoV4PublicVerify() {
+func Example_pasetoV4PublicVerify() {
 	// Use this a random source, it must be replaced by rand.Reader for production use.
 	deterministicSeedForTest := bytes.NewReader([]byte("deterministic-random-source-for-tests-1234567890123456789012345678901234567890"))
 
@@ -182,3 +182,24 @@ func ExamplePasetoV4PublicVerify() {
 	fmt.Printf("%s", m)
 	// Output: my super secret message
 }
+
+func Example_pasetoV4LocalKeyIDFooter() {
+	// Use this a random source, it must be replaced by rand.Reader for production use.
+	deterministicSeedForTest := bytes.NewReader([]byte("deterministic-random-source-for-tests-1234567890123456789012345678901234567890"))
+
+	// Generate an encryption key.
+	localKey, err := pasetov4.GenerateLocalKey(deterministicSeedForTest)
+	if err != nil {
+		panic(err)
+	}
+
+	// Identify the key with its PASERK identifier (k4.lid). The kid values of
+	// the official test vectors are opaque samples and can't be derived.
+	footer, err := pasetov4.LocalKeyIDFooter(localKey)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("%s", footer)
+	// Output: {"kid":"k4.lid.P_we45ip3gHhB8FUHlra3Y-FWXrFOZ5uy7MzIA7jnci4"}
+}
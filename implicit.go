@@ -0,0 +1,57 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import "errors"
+
+// VersionedImplicit returns the implicit assertion `v || data` so that the
+// binding semantics can evolve by bumping the context version.
+func VersionedImplicit(v uint8, data []byte) []byte {
+	out := make([]byte, 0, 1+len(data))
+	out = append(out, v)
+	out = append(out, data...)
+
+	return out
+}
+
+// OpenWithImplicitVersions calls open (a decrypt or verify closure) with the
+// versioned implicit assertion of each allowed version, in the given order,
+// and returns the payload and the version of the first success.
+//
+// The implicit assertion is not part of the token, so each allowed version is
+// tried. List the current version first to keep the common path fast.
+func OpenWithImplicitVersions(allowed []uint8, data []byte, open func(i []byte) ([]byte, error)) ([]byte, uint8, error) {
+	// Check arguments
+	if len(allowed) == 0 {
+		return nil, 0, errors.New("paseto: at least one implicit assertion version is required")
+	}
+	if open == nil {
+		return nil, 0, errors.New("paseto: open function is nil")
+	}
+
+	var lastErr error
+	for _, v := range allowed {
+		payload, err := open(VersionedImplicit(v, data))
+		if err == nil {
+			return payload, v, nil
+		}
+		lastErr = err
+	}
+
+	return nil, 0, lastErr
+}
@@ -0,0 +1,67 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pasetov4 "zntr.io/paseto/v4"
+)
+
+func TestVersionedImplicit(t *testing.T) {
+	assert.Equal(t, []byte{0x02, 'c', 't', 'x'}, VersionedImplicit(2, []byte("ctx")))
+	assert.Equal(t, []byte{0x01}, VersionedImplicit(1, nil))
+}
+
+func TestOpenWithImplicitVersions(t *testing.T) {
+	key, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	data := []byte("{\"client\":\"device-1\"}")
+
+	// Token minted under the old binding rule
+	token, err := pasetov4.Encrypt(rand.Reader, key, m, nil, VersionedImplicit(1, data))
+	assert.NoError(t, err)
+
+	open := func(i []byte) ([]byte, error) {
+		return pasetov4.Decrypt(key, token, nil, i)
+	}
+
+	p, v, err := OpenWithImplicitVersions([]uint8{2, 1}, data, open)
+	assert.NoError(t, err)
+	assert.Equal(t, uint8(1), v)
+	assert.Equal(t, m, p)
+
+	// Old version not allowed anymore
+	_, _, err = OpenWithImplicitVersions([]uint8{2}, data, open)
+	assert.ErrorIs(t, err, pasetov4.ErrInvalidMAC)
+
+	// Different data
+	_, _, err = OpenWithImplicitVersions([]uint8{2, 1}, []byte("other"), open)
+	assert.Error(t, err)
+
+	// Invalid arguments
+	_, _, err = OpenWithImplicitVersions(nil, data, open)
+	assert.Error(t, err)
+	_, _, err = OpenWithImplicitVersions([]uint8{1}, data, nil)
+	assert.Error(t, err)
+}
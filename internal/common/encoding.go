@@ -0,0 +1,66 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package common
+
+import (
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"strings"
+)
+
+// DecodeHexKey decodes a hex-encoded key and ensures that it is exactly n
+// bytes long. Surrounding whitespaces are ignored.
+func DecodeHexKey(s string, n int) ([]byte, error) {
+	raw, err := hex.DecodeString(strings.TrimSpace(s))
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to decode hex key: %w", err)
+	}
+
+	return checkKeyLength(raw, n)
+}
+
+// DecodeBase64Key decodes a base64-encoded key and ensures that it is exactly
+// n bytes long. Both the standard and the URL-safe alphabets are accepted,
+// with or without padding. Surrounding whitespaces are ignored.
+func DecodeBase64Key(s string, n int) ([]byte, error) {
+	s = strings.TrimRight(strings.TrimSpace(s), "=")
+
+	enc := base64.RawStdEncoding
+	if strings.ContainsAny(s, "-_") {
+		enc = base64.RawURLEncoding
+	}
+
+	raw, err := enc.Strict().DecodeString(s)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to decode base64 key: %w", err)
+	}
+
+	return checkKeyLength(raw, n)
+}
+
+// -----------------------------------------------------------------------------
+
+func checkKeyLength(raw []byte, n int) ([]byte, error) {
+	if len(raw) != n {
+		return nil, fmt.Errorf("%w, it must be %d bytes long, got %d", ErrInvalidKeyLength, n, len(raw))
+	}
+
+	// No error
+	return raw, nil
+}
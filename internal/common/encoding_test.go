@@ -0,0 +1,53 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package common
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecodeBase64Key(t *testing.T) {
+	// 0xfb 0xff encodes with the alphabet specific characters.
+	expected := []byte{0xfb, 0xff, 0xfe}
+
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr bool
+	}{
+		{name: "standard", input: "+//+"},
+		{name: "url-safe", input: "-__-"},
+		{name: "surrounding whitespaces", input: " +//+\n"},
+		{name: "mixed alphabets", input: "+_/-", wantErr: true},
+		{name: "invalid", input: "+//", wantErr: true},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			raw, err := DecodeBase64Key(testCase.input, len(expected))
+			if testCase.wantErr {
+				assert.Error(t, err)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, expected, raw)
+		})
+	}
+}
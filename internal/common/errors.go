@@ -0,0 +1,66 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrNilKey is raised when a required key is nil.
+	ErrNilKey = errors.New("paseto: key is nil")
+	// ErrInvalidKeyLength is raised when a key doesn't have the expected length.
+	ErrInvalidKeyLength = errors.New("paseto: invalid key length")
+	// ErrInvalidToken is raised when a token is malformed.
+	ErrInvalidToken = errors.New("paseto: invalid token")
+	// ErrFooterTooLarge is raised when a token footer exceeds the configured
+	// maximum length.
+	ErrFooterTooLarge = errors.New("paseto: footer is too large")
+	// ErrInvalidMAC is raised when a local token MAC doesn't match.
+	ErrInvalidMAC = errors.New("paseto: invalid pre-authentication header")
+	// ErrInvalidSignature is raised when a public token signature doesn't
+	// match.
+	ErrInvalidSignature = errors.New("paseto: invalid token signature")
+	// ErrPossibleImplicitMismatch is wrapped with the authentication errors
+	// when an implicit assertion was given, since a mismatch between the
+	// producer and consumer implicit assertions is a common failure cause.
+	ErrPossibleImplicitMismatch = errors.New("paseto: possible implicit assertion mismatch")
+)
+
+var (
+	// ErrMissingFooter is raised when a footer is expected but the token
+	// doesn't have one. It wraps ErrInvalidToken.
+	ErrMissingFooter = fmt.Errorf("%w, footer is missing but expected", ErrInvalidToken)
+	// ErrWrongVersion is raised when a well-formed PASETO header has another
+	// version than the expected one. It wraps ErrInvalidToken.
+	ErrWrongVersion = fmt.Errorf("%w, wrong version", ErrInvalidToken)
+	// ErrWrongPurpose is raised when a well-formed PASETO header has another
+	// purpose than the expected one. It wraps ErrInvalidToken.
+	ErrWrongPurpose = fmt.Errorf("%w, wrong purpose", ErrInvalidToken)
+)
+
+// AuthError adds the ErrPossibleImplicitMismatch hint to the authentication
+// error (err) when a non-empty implicit assertion (i) was given.
+func AuthError(err error, i []byte) error {
+	if len(i) == 0 {
+		return err
+	}
+
+	return fmt.Errorf("%w (%w)", err, ErrPossibleImplicitMismatch)
+}
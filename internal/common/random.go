@@ -0,0 +1,69 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package common
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// maxEmptyReads is the number of consecutive empty reads tolerated from a
+// random source before giving up.
+const maxEmptyReads = 100
+
+// ReadRandom fills dst from the random source (r). It behaves like
+// io.ReadFull but also guards against misbehaving readers: an invalid byte
+// count is rejected and a reader returning no data without error fails with
+// io.ErrNoProgress instead of looping forever.
+func ReadRandom(r io.Reader, dst []byte) error {
+	// Check arguments
+	if r == nil {
+		return errors.New("random source is nil")
+	}
+
+	read, empty := 0, 0
+	for read < len(dst) {
+		n, err := r.Read(dst[read:])
+		if n < 0 || n > len(dst)-read {
+			return fmt.Errorf("random source returned an invalid byte count (%d)", n)
+		}
+		read += n
+		if read == len(dst) {
+			break
+		}
+
+		// Handle short reads
+		if err != nil {
+			if errors.Is(err, io.EOF) {
+				err = io.ErrUnexpectedEOF
+			}
+			return fmt.Errorf("short read from random source (%d of %d bytes): %w", read, len(dst), err)
+		}
+		if n > 0 {
+			empty = 0
+			continue
+		}
+		if empty++; empty >= maxEmptyReads {
+			return fmt.Errorf("short read from random source (%d of %d bytes): %w", read, len(dst), io.ErrNoProgress)
+		}
+	}
+
+	// No error
+	return nil
+}
@@ -0,0 +1,70 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package common
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"testing"
+	"testing/iotest"
+
+	"github.com/stretchr/testify/assert"
+)
+
+type readerFunc func(p []byte) (int, error)
+
+func (f readerFunc) Read(p []byte) (int, error) {
+	return f(p)
+}
+
+func TestReadRandom(t *testing.T) {
+	errSource := errors.New("source failure")
+
+	testCases := []struct {
+		name    string
+		r       io.Reader
+		wantErr bool
+		errIs   error
+	}{
+		{name: "full", r: bytes.NewReader(make([]byte, 32))},
+		{name: "one byte at a time", r: iotest.OneByteReader(bytes.NewReader(make([]byte, 32)))},
+		{name: "data with EOF", r: iotest.DataErrReader(bytes.NewReader(make([]byte, 32)))},
+		{name: "short", r: bytes.NewReader(make([]byte, 16)), wantErr: true, errIs: io.ErrUnexpectedEOF},
+		{name: "empty", r: bytes.NewReader(nil), wantErr: true, errIs: io.ErrUnexpectedEOF},
+		{name: "error", r: iotest.ErrReader(errSource), wantErr: true, errIs: errSource},
+		{name: "no progress", r: readerFunc(func([]byte) (int, error) { return 0, nil }), wantErr: true, errIs: io.ErrNoProgress},
+		{name: "invalid count", r: readerFunc(func(p []byte) (int, error) { return len(p) + 1, nil }), wantErr: true},
+		{name: "nil reader", r: nil, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ReadRandom(testCase.r, make([]byte, 32))
+			if !testCase.wantErr {
+				assert.NoError(t, err)
+				return
+			}
+			assert.Error(t, err)
+			if testCase.errIs != nil {
+				assert.ErrorIs(t, err, testCase.errIs)
+			}
+		})
+	}
+}
@@ -0,0 +1,205 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package common
+
+import (
+	"bytes"
+	"crypto/subtle"
+	"encoding/base64"
+	"fmt"
+	"strings"
+)
+
+// SplitToken splits the token content (header already removed) in body and
+// footer parts. The footer is nil when the token doesn't have one.
+//
+// Line breaks are rejected because they are silently skipped by the base64
+// decoder.
+func SplitToken(raw []byte) (body, footer []byte, err error) {
+	// Check line breaks
+	if bytes.ContainsAny(raw, "\r\n") {
+		return nil, nil, fmt.Errorf("%w, unexpected line break", ErrInvalidToken)
+	}
+
+	// Split the body and the footer
+	parts := bytes.SplitN(raw, []byte("."), 3)
+	switch len(parts) {
+	case 1:
+		body = parts[0]
+	case 2:
+		body, footer = parts[0], parts[1]
+		if len(footer) == 0 {
+			return nil, nil, fmt.Errorf("%w, footer is empty", ErrInvalidToken)
+		}
+	default:
+		return nil, nil, fmt.Errorf("%w, too many segments", ErrInvalidToken)
+	}
+
+	// Check body
+	if len(body) == 0 {
+		return nil, nil, fmt.Errorf("%w, body is empty", ErrInvalidToken)
+	}
+
+	// No error
+	return body, footer, nil
+}
+
+// SplitLocalBody splits a decoded local token body into its nonce, ciphertext
+// and MAC. The body must hold at least a full nonce and a full MAC, a body
+// made of the MAC only (or of the nonce only) is rejected before any slicing.
+// The returned slices are capped so that appending to one of them can't
+// overwrite the next one.
+func SplitLocalBody(raw []byte, nonceLen, macLen int) (n, c, t []byte, err error) {
+	// Check body length
+	if len(raw) < nonceLen+macLen {
+		return nil, nil, nil, fmt.Errorf("%w body, it is too short, it must be %d bytes long at least", ErrInvalidToken, nonceLen+macLen)
+	}
+
+	// Extract components
+	macOffset := len(raw) - macLen
+	n = raw[:nonceLen:nonceLen]
+	c = raw[nonceLen:macOffset:macOffset]
+	t = raw[macOffset:]
+
+	// No error
+	return n, c, t, nil
+}
+
+// CheckFooterLength ensures that the decoded footer doesn't exceed maxLength
+// bytes, without decoding it. A maxLength lower or equal to 0 disables the
+// check.
+func CheckFooterLength(rawFooter []byte, maxLength int) error {
+	if maxLength <= 0 {
+		return nil
+	}
+	if base64.RawURLEncoding.DecodedLen(len(rawFooter)) > maxLength {
+		return fmt.Errorf("%w, it must be %d bytes long at most", ErrFooterTooLarge, maxLength)
+	}
+
+	// No error
+	return nil
+}
+
+// DecodeToken checks the token header and returns the decoded body and footer.
+// The footer length is checked against maxFooterLength before decoding.
+func DecodeToken(prefix, input string, maxFooterLength int) (body, footer []byte, err error) {
+	rawToken := []byte(input)
+
+	// Check token header
+	if err := CheckHeader(rawToken, prefix); err != nil {
+		return nil, nil, err
+	}
+
+	// Trim prefix
+	rawToken = rawToken[len(prefix):]
+
+	// Split the footer and the body
+	rawBody, rawFooter, err := SplitToken(rawToken)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check footer size before decoding
+	if err := CheckFooterLength(rawFooter, maxFooterLength); err != nil {
+		return nil, nil, err
+	}
+
+	// Decode footer
+	if len(rawFooter) > 0 {
+		footer = make([]byte, base64.RawURLEncoding.DecodedLen(len(rawFooter)))
+		if _, err := base64.RawURLEncoding.Decode(footer, rawFooter); err != nil {
+			return nil, nil, fmt.Errorf("%w, footer has invalid encoding: %v", ErrInvalidToken, err)
+		}
+	}
+
+	// Decode body
+	body = make([]byte, base64.RawURLEncoding.DecodedLen(len(rawBody)))
+	if _, err := base64.RawURLEncoding.Decode(body, rawBody); err != nil {
+		return nil, nil, fmt.Errorf("%w body: %v", ErrInvalidToken, err)
+	}
+
+	// No error
+	return body, footer, nil
+}
+
+// CheckFooter compares the expected footer (if any) with the token footer. A
+// token footer is accepted when none is expected, unless strict is set; it is
+// then authenticated against the empty expected footer and rejected by the
+// MAC or signature check.
+func CheckFooter(expected, footer []byte, strict bool) error {
+	if len(expected) == 0 {
+		if strict && len(footer) > 0 {
+			return fmt.Errorf("%w, footer is present but not expected", ErrInvalidToken)
+		}
+		return nil
+	}
+	if len(footer) == 0 {
+		return ErrMissingFooter
+	}
+
+	// Compare footer
+	if subtle.ConstantTimeCompare(expected, footer) == 0 {
+		return fmt.Errorf("%w, footer mismatch", ErrInvalidToken)
+	}
+
+	// No error
+	return nil
+}
+
+// CheckHeader ensures that the token starts with the expected header
+// (`v4.local.`). A well-formed header with another version or purpose is
+// reported with ErrWrongVersion or ErrWrongPurpose, any other content with
+// ErrInvalidToken.
+func CheckHeader(raw []byte, expected string) error {
+	if bytes.HasPrefix(raw, []byte(expected)) {
+		return nil
+	}
+
+	// Extract `version.purpose.` from the token
+	version, rest, ok := bytes.Cut(raw, []byte("."))
+	if !ok || !isVersion(version) {
+		return ErrInvalidToken
+	}
+	purpose, _, ok := bytes.Cut(rest, []byte("."))
+	if !ok || (string(purpose) != "local" && string(purpose) != "public") {
+		return ErrInvalidToken
+	}
+
+	// Compare with the expected header
+	expectedVersion, expectedPurpose, _ := strings.Cut(strings.TrimSuffix(expected, "."), ".")
+	if string(version) != expectedVersion {
+		return fmt.Errorf("%w: got %q, expected %q", ErrWrongVersion, version, expectedVersion)
+	}
+
+	return fmt.Errorf("%w: got %q, expected %q", ErrWrongPurpose, purpose, expectedPurpose)
+}
+
+// isVersion returns true for `v` followed by digits and an optional lowercase
+// suffix (`v4`, `v4x`).
+func isVersion(version []byte) bool {
+	if len(version) < 2 || version[0] != 'v' || version[1] < '0' || version[1] > '9' {
+		return false
+	}
+	for _, c := range version[2:] {
+		if (c < '0' || c > '9') && (c < 'a' || c > 'z') {
+			return false
+		}
+	}
+
+	return true
+}
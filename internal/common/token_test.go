@@ -0,0 +1,147 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package common
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSplitToken(t *testing.T) {
+	testCases := []struct {
+		name       string
+		input      string
+		wantBody   string
+		wantFooter string
+		wantErr    bool
+	}{
+		{name: "blank", input: "", wantErr: true},
+		{name: "no dot", input: "body", wantBody: "body"},
+		{name: "one dot", input: "body.footer", wantBody: "body", wantFooter: "footer"},
+		{name: "leading dot", input: ".footer", wantErr: true},
+		{name: "trailing dot", input: "body.", wantErr: true},
+		{name: "two dots", input: "body.foo.ter", wantErr: true},
+		{name: "only dots", input: "...", wantErr: true},
+		{name: "line feed", input: "body.footer\n", wantErr: true},
+		{name: "carriage return", input: "bo\rdy", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			body, footer, err := SplitToken([]byte(testCase.input))
+			if (err != nil) != testCase.wantErr {
+				t.Errorf("error during the split call, error = %v, wantErr %v", err, testCase.wantErr)
+				return
+			}
+			assert.Equal(t, testCase.wantBody, string(body))
+			assert.Equal(t, testCase.wantFooter, string(footer))
+		})
+	}
+}
+
+func TestSplitLocalBody(t *testing.T) {
+	const nonceLen, macLen = 32, 48
+
+	raw := make([]byte, nonceLen+3+macLen)
+	for i := range raw {
+		raw[i] = byte(i)
+	}
+
+	n, c, tag, err := SplitLocalBody(raw, nonceLen, macLen)
+	assert.NoError(t, err)
+	assert.Equal(t, raw[:nonceLen], n)
+	assert.Equal(t, raw[nonceLen:nonceLen+3], c)
+	assert.Equal(t, raw[nonceLen+3:], tag)
+
+	// Appending to the ciphertext doesn't overwrite the MAC
+	expected := bytes.Clone(tag)
+	_ = append(c, 0xff)
+	_ = append(n, 0xff)
+	assert.Equal(t, expected, tag)
+	assert.Equal(t, byte(nonceLen), c[0])
+
+	// Empty ciphertext
+	n, c, tag, err = SplitLocalBody(raw[3:], nonceLen, macLen)
+	assert.NoError(t, err)
+	assert.Len(t, n, nonceLen)
+	assert.Empty(t, c)
+	assert.Len(t, tag, macLen)
+
+	// Degenerate bodies
+	for _, l := range []int{0, 1, macLen, nonceLen, nonceLen + macLen - 1} {
+		_, _, _, err = SplitLocalBody(raw[:l], nonceLen, macLen)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	}
+}
+
+func TestCheckFooterLength(t *testing.T) {
+	assert.NoError(t, CheckFooterLength(nil, 4))
+	// 6 characters decode to 4 bytes
+	assert.NoError(t, CheckFooterLength([]byte("AAAAAA"), 4))
+	assert.ErrorIs(t, CheckFooterLength([]byte("AAAAAAA"), 4), ErrFooterTooLarge)
+	// No limit
+	assert.NoError(t, CheckFooterLength([]byte("AAAAAAA"), 0))
+}
+
+func TestCheckFooter(t *testing.T) {
+	f := []byte(`{"kid":"1234"}`)
+
+	assert.NoError(t, CheckFooter(nil, nil, true))
+	assert.NoError(t, CheckFooter(nil, f, false))
+	assert.ErrorIs(t, CheckFooter(nil, f, true), ErrInvalidToken)
+	assert.NoError(t, CheckFooter(f, f, false))
+	assert.ErrorIs(t, CheckFooter(f, nil, false), ErrMissingFooter)
+	assert.ErrorIs(t, CheckFooter(f, []byte(`{}`), false), ErrInvalidToken)
+}
+
+func TestCheckHeader(t *testing.T) {
+	testCases := []struct {
+		name    string
+		input   string
+		wantErr error
+	}{
+		{name: "valid", input: "v4.local.AAAA"},
+		{name: "blank", input: "", wantErr: ErrInvalidToken},
+		{name: "garbage", input: "garbage", wantErr: ErrInvalidToken},
+		{name: "unknown purpose", input: "v4.secret.AAAA", wantErr: ErrInvalidToken},
+		{name: "invalid version", input: "version.local.AAAA", wantErr: ErrInvalidToken},
+		{name: "wrong version", input: "v3.local.AAAA", wantErr: ErrWrongVersion},
+		{name: "wrong suffixed version", input: "v4x.local.AAAA", wantErr: ErrWrongVersion},
+		{name: "wrong purpose", input: "v4.public.AAAA", wantErr: ErrWrongPurpose},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			err := CheckHeader([]byte(testCase.input), "v4.local.")
+			if testCase.wantErr == nil {
+				assert.NoError(t, err)
+				return
+			}
+			assert.ErrorIs(t, err, testCase.wantErr)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	// Detected values are reported
+	assert.EqualError(t, CheckHeader([]byte("v2.local.AAAA"), "v4.local."), `paseto: invalid token, wrong version: got "v2", expected "v4"`)
+	assert.EqualError(t, CheckHeader([]byte("v4.public.AAAA"), "v4.local."), `paseto: invalid token, wrong purpose: got "public", expected "local"`)
+}
@@ -0,0 +1,210 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package migrate provides helpers to move from JWT to PASETO, and between
+// PASETO variants.
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"time"
+
+	"zntr.io/paseto/claims"
+)
+
+// ErrUnsupportedAlgorithm is raised when a JWT algorithm has no PASETO
+// equivalent.
+var ErrUnsupportedAlgorithm = errors.New("paseto: unsupported JWT algorithm")
+
+// HeaderForAlgorithm returns the PASETO header to use as a replacement for the
+// given JWT algorithm.
+//
+// Symmetric algorithms are mapped to v4.local, EdDSA to v4.public, ES384 to
+// v3.public. All others (including "none") are rejected.
+func HeaderForAlgorithm(alg string) (string, error) {
+	switch alg {
+	case "HS256", "HS384", "HS512":
+		return "v4.local.", nil
+	case "EdDSA":
+		return "v4.public.", nil
+	case "ES384":
+		return "v3.public.", nil
+	default:
+		return "", fmt.Errorf("%w: %q", ErrUnsupportedAlgorithm, alg)
+	}
+}
+
+// FromJWTClaims converts JWT claims to PASETO claims.
+//
+// Temporal claims (exp, nbf, iat) are converted from numeric epoch (JWT) to
+// RFC3339 dates (PASETO), all other claims are kept as-is.
+func FromJWTClaims(jwtClaims map[string]any) (*claims.Claims, error) {
+	// Check arguments
+	if jwtClaims == nil {
+		return nil, errors.New("paseto: jwt claims must not be nil")
+	}
+
+	out := &claims.Claims{}
+	for k, v := range jwtClaims {
+		var err error
+		switch k {
+		case "iss":
+			out.Issuer, err = stringClaim(k, v)
+		case "sub":
+			out.Subject, err = stringClaim(k, v)
+		case "aud":
+			out.Audience, err = audienceClaim(k, v)
+		case "jti":
+			out.ID, err = stringClaim(k, v)
+		case "exp":
+			out.Expiration, err = numericDateClaim(k, v)
+		case "nbf":
+			out.NotBefore, err = numericDateClaim(k, v)
+		case "iat":
+			out.IssuedAt, err = numericDateClaim(k, v)
+		default:
+			if out.Custom == nil {
+				out.Custom = map[string]any{}
+			}
+			out.Custom[k] = v
+		}
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// No error
+	return out, nil
+}
+
+// ToJWTClaims converts PASETO claims to JWT claims.
+//
+// Temporal claims (exp, nbf, iat) are converted from RFC3339 dates (PASETO) to
+// numeric epoch (JWT), all other claims are kept as-is.
+func ToJWTClaims(c *claims.Claims) (map[string]any, error) {
+	// Check arguments
+	if c == nil {
+		return nil, errors.New("paseto: claims must not be nil")
+	}
+
+	out := map[string]any{}
+	for k, v := range c.Custom {
+		if claims.IsRegistered(k) {
+			return nil, fmt.Errorf("paseto: custom claim %q overrides a registered claim", k)
+		}
+		out[k] = v
+	}
+
+	if c.Issuer != "" {
+		out["iss"] = c.Issuer
+	}
+	if c.Subject != "" {
+		out["sub"] = c.Subject
+	}
+	if len(c.Audience) == 1 {
+		out["aud"] = c.Audience[0]
+	} else if len(c.Audience) > 1 {
+		out["aud"] = []string(c.Audience)
+	}
+	if c.ID != "" {
+		out["jti"] = c.ID
+	}
+	if c.Expiration != nil {
+		out["exp"] = c.Expiration.Unix()
+	}
+	if c.NotBefore != nil {
+		out["nbf"] = c.NotBefore.Unix()
+	}
+	if c.IssuedAt != nil {
+		out["iat"] = c.IssuedAt.Unix()
+	}
+
+	// No error
+	return out, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func stringClaim(name string, v any) (string, error) {
+	s, ok := v.(string)
+	if !ok {
+		return "", fmt.Errorf("paseto: invalid %q claim, it must be a string", name)
+	}
+
+	// No error
+	return s, nil
+}
+
+func audienceClaim(name string, v any) (claims.Audience, error) {
+	switch aud := v.(type) {
+	case string:
+		return claims.Audience{aud}, nil
+	case []string:
+		return claims.Audience(aud), nil
+	case []any:
+		out := make(claims.Audience, 0, len(aud))
+		for _, item := range aud {
+			s, err := stringClaim(name, item)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, fmt.Errorf("paseto: invalid %q claim, it must be a string or an array of strings", name)
+	}
+}
+
+func numericDateClaim(name string, v any) (*time.Time, error) {
+	var sec float64
+	switch n := v.(type) {
+	case float64:
+		sec = n
+	case float32:
+		sec = float64(n)
+	case int:
+		sec = float64(n)
+	case int32:
+		sec = float64(n)
+	case int64:
+		sec = float64(n)
+	case json.Number:
+		f, err := n.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("paseto: invalid %q claim: %w", name, err)
+		}
+		sec = f
+	default:
+		return nil, fmt.Errorf("paseto: invalid %q claim, it must be a numeric date", name)
+	}
+
+	// Check value
+	if math.IsNaN(sec) || math.IsInf(sec, 0) {
+		return nil, fmt.Errorf("paseto: invalid %q claim, it must be a finite numeric date", name)
+	}
+
+	// Convert to time (second precision, fractional part kept as nanoseconds)
+	whole, frac := math.Modf(sec)
+	t := time.Unix(int64(whole), int64(frac*1e9)).UTC()
+
+	// No error
+	return &t, nil
+}
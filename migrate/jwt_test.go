@@ -0,0 +1,111 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package migrate
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"zntr.io/paseto/claims"
+)
+
+func TestFromJWTClaims(t *testing.T) {
+	var jwtClaims map[string]any
+	err := json.Unmarshal([]byte(`{"iss":"https://issuer.example.com","sub":"user-123","aud":"api","exp":1641000000,"nbf":1640990000,"iat":1640990000,"jti":"1234567890","scope":"openid"}`), &jwtClaims)
+	assert.NoError(t, err)
+
+	c, err := FromJWTClaims(jwtClaims)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://issuer.example.com", c.Issuer)
+	assert.Equal(t, "user-123", c.Subject)
+	assert.Equal(t, claims.Audience{"api"}, c.Audience)
+	assert.Equal(t, "1234567890", c.ID)
+	assert.Equal(t, time.Unix(1641000000, 0).UTC(), *c.Expiration)
+	assert.Equal(t, time.Unix(1640990000, 0).UTC(), *c.NotBefore)
+	assert.Equal(t, time.Unix(1640990000, 0).UTC(), *c.IssuedAt)
+	assert.Equal(t, map[string]any{"scope": "openid"}, c.Custom)
+
+	// PASETO dates are encoded using RFC3339
+	raw, err := json.Marshal(c)
+	assert.NoError(t, err)
+	assert.Contains(t, string(raw), `"exp":"2022-01-01T01:20:00Z"`)
+
+	// Reverse
+	out, err := ToJWTClaims(c)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{
+		"iss":   "https://issuer.example.com",
+		"sub":   "user-123",
+		"aud":   "api",
+		"exp":   int64(1641000000),
+		"nbf":   int64(1640990000),
+		"iat":   int64(1640990000),
+		"jti":   "1234567890",
+		"scope": "openid",
+	}, out)
+}
+
+func TestFromJWTClaims_AudienceArray(t *testing.T) {
+	c, err := FromJWTClaims(map[string]any{"aud": []any{"api", "admin"}})
+	assert.NoError(t, err)
+	assert.Equal(t, claims.Audience{"api", "admin"}, c.Audience)
+
+	out, err := ToJWTClaims(c)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"api", "admin"}, out["aud"])
+}
+
+func TestFromJWTClaims_Invalid(t *testing.T) {
+	testCases := []struct {
+		name   string
+		claims map[string]any
+	}{
+		{name: "nil", claims: nil},
+		{name: "string exp", claims: map[string]any{"exp": "2022-01-01T00:00:00Z"}},
+		{name: "numeric sub", claims: map[string]any{"sub": 1234}},
+		{name: "numeric aud", claims: map[string]any{"aud": []any{"api", 1234}}},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := FromJWTClaims(testCase.claims)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func TestHeaderForAlgorithm(t *testing.T) {
+	h, err := HeaderForAlgorithm("EdDSA")
+	assert.NoError(t, err)
+	assert.Equal(t, "v4.public.", h)
+
+	h, err = HeaderForAlgorithm("HS256")
+	assert.NoError(t, err)
+	assert.Equal(t, "v4.local.", h)
+
+	_, err = HeaderForAlgorithm("none")
+	assert.True(t, errors.Is(err, ErrUnsupportedAlgorithm))
+
+	_, err = HeaderForAlgorithm("RS256")
+	assert.True(t, errors.Is(err, ErrUnsupportedAlgorithm))
+}
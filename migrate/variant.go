@@ -0,0 +1,102 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package migrate
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"zntr.io/paseto"
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+// V4ToV4x decrypts a v4.local token and encrypts its payload again as a
+// v4x.local token with a new nonce read from r. The footer and the implicit
+// assertion (i) are preserved.
+func V4ToV4x(r io.Reader, token string, from *pasetov4.LocalKey, to *pasetov4x.LocalKey, i []byte) (string, error) {
+	// Check arguments
+	if from == nil || to == nil {
+		return "", pasetov4.ErrNilKey
+	}
+
+	// Read the footer
+	footer, err := tokenFooter(token, pasetov4.LocalPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	// Decrypt the token
+	m, err := pasetov4.Decrypt(from, token, footer, i)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to convert token: %w", err)
+	}
+
+	// No error
+	return pasetov4x.Encrypt(r, to, m, footer, i)
+}
+
+// V4xToV4 decrypts a v4x.local token and encrypts its payload again as a
+// v4.local token with a new nonce read from r. The footer and the implicit
+// assertion (i) are preserved.
+func V4xToV4(r io.Reader, token string, from *pasetov4x.LocalKey, to *pasetov4.LocalKey, i []byte) (string, error) {
+	// Check arguments
+	if from == nil || to == nil {
+		return "", pasetov4.ErrNilKey
+	}
+
+	// Read the footer
+	footer, err := tokenFooter(token, pasetov4x.LocalPrefix)
+	if err != nil {
+		return "", err
+	}
+
+	// Decrypt the token
+	m, err := pasetov4x.Decrypt(from, token, footer, i)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to convert token: %w", err)
+	}
+
+	// No error
+	return pasetov4.Encrypt(r, to, m, footer, i)
+}
+
+// -----------------------------------------------------------------------------
+
+// tokenFooter checks the token header and returns its decoded footer, it is
+// authenticated by the decryption.
+func tokenFooter(token, prefix string) ([]byte, error) {
+	// Check token structure
+	header, _, rawFooter, err := paseto.Split(token)
+	if err != nil {
+		return nil, err
+	}
+	if header != prefix {
+		return nil, fmt.Errorf("%w, expected a %q token", paseto.ErrInvalidToken, prefix)
+	}
+
+	// Decode footer
+	footer, err := base64.RawURLEncoding.DecodeString(rawFooter)
+	if err != nil {
+		return nil, fmt.Errorf("%w, footer has invalid encoding: %v", paseto.ErrInvalidToken, err)
+	}
+
+	// No error
+	return footer, nil
+}
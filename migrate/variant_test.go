@@ -0,0 +1,77 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package migrate
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"zntr.io/paseto"
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+func TestV4ToV4x(t *testing.T) {
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k4x, err := pasetov4x.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte(`{"sub":"user-1"}`)
+	i := []byte("implicit")
+
+	for _, f := range [][]byte{nil, []byte(`{"kid":"1234"}`)} {
+		token, err := pasetov4.Encrypt(rand.Reader, k4, m, f, i)
+		assert.NoError(t, err)
+
+		// v4 to v4x
+		converted, err := V4ToV4x(rand.Reader, token, k4, k4x, i)
+		assert.NoError(t, err)
+		assert.True(t, pasetov4x.IsLocal(converted))
+		p, err := pasetov4x.Decrypt(k4x, converted, f, i)
+		assert.NoError(t, err)
+		assert.Equal(t, m, p)
+
+		// Back to v4, with a new nonce
+		back, err := V4xToV4(rand.Reader, converted, k4x, k4, i)
+		assert.NoError(t, err)
+		assert.NotEqual(t, token, back)
+		p, err = pasetov4.Decrypt(k4, back, f, i)
+		assert.NoError(t, err)
+		assert.Equal(t, m, p)
+	}
+
+	token, err := pasetov4.Encrypt(rand.Reader, k4, m, nil, i)
+	assert.NoError(t, err)
+
+	// Implicit assertion mismatch
+	_, err = V4ToV4x(rand.Reader, token, k4, k4x, nil)
+	assert.ErrorIs(t, err, pasetov4.ErrInvalidMAC)
+
+	// Wrong variant
+	_, err = V4xToV4(rand.Reader, token, k4x, k4, i)
+	assert.ErrorIs(t, err, paseto.ErrInvalidToken)
+
+	// Nil keys
+	_, err = V4ToV4x(rand.Reader, token, nil, k4x, i)
+	assert.ErrorIs(t, err, pasetov4.ErrNilKey)
+	_, err = V4xToV4(rand.Reader, token, k4x, nil, i)
+	assert.ErrorIs(t, err, pasetov4.ErrNilKey)
+}
@@ -0,0 +1,97 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"fmt"
+	"strings"
+
+	"zntr.io/paseto/internal/common"
+)
+
+// ErrInvalidToken is raised when a token is malformed.
+var ErrInvalidToken = common.ErrInvalidToken
+
+// Normalize trims the surrounding whitespaces of the given token and checks
+// its structure before any version specific processing.
+//
+// The token must only contain base64url characters and dots, start with a
+// supported header and have a non-empty body and an optional non-empty footer.
+func Normalize(token string) (string, error) {
+	// Trim surrounding whitespaces
+	token = strings.TrimSpace(token)
+	if token == "" {
+		return "", fmt.Errorf("%w, token is blank", ErrInvalidToken)
+	}
+
+	// Check charset
+	for j := 0; j < len(token); j++ {
+		if !isTokenChar(token[j]) {
+			return "", fmt.Errorf("%w, unexpected character at position %d", ErrInvalidToken, j)
+		}
+	}
+
+	// Check header
+	h, ok := HeaderOf(token)
+	if !ok {
+		return "", fmt.Errorf("%w, unsupported header", ErrInvalidToken)
+	}
+
+	// Check segments
+	if _, _, err := common.SplitToken([]byte(token[len(h.Prefix):])); err != nil {
+		return "", err
+	}
+
+	// No error
+	return token, nil
+}
+
+// Split checks the token structure with Normalize and returns its segments
+// without decoding them. The header is the token prefix with its trailing dot
+// (`v4.local.`) and the footer is empty when the token doesn't have one.
+func Split(token string) (header, body, footer string, err error) {
+	// Check token structure
+	token, err = Normalize(token)
+	if err != nil {
+		return "", "", "", err
+	}
+
+	// Normalize already checked the header
+	h, _ := HeaderOf(token)
+
+	// Split the footer and the body
+	body, footer, _ = strings.Cut(token[len(h.Prefix):], ".")
+
+	// No error
+	return h.Prefix, body, footer, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// isTokenChar returns true for the base64url alphabet and the segment
+// separator.
+func isTokenChar(c byte) bool {
+	switch {
+	case c >= 'A' && c <= 'Z', c >= 'a' && c <= 'z', c >= '0' && c <= '9':
+		return true
+	case c == '-', c == '_', c == '.':
+		return true
+	default:
+		return false
+	}
+}
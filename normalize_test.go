@@ -0,0 +1,93 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	testCases := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr bool
+	}{
+		{name: "blank", token: "", wantErr: true},
+		{name: "whitespaces only", token: " \t\n", wantErr: true},
+		{name: "valid", token: "v4.local.AAAA", want: "v4.local.AAAA"},
+		{name: "valid with footer", token: "v4.public.AA-_.BB", want: "v4.public.AA-_.BB"},
+		{name: "surrounding whitespaces", token: " \tv3.local.AAAA\r\n", want: "v3.local.AAAA"},
+		{name: "inner whitespace", token: "v4.local.AA AA", wantErr: true},
+		{name: "url encoded", token: "v4.local.AA%3D", wantErr: true},
+		{name: "padding", token: "v4.local.AAA=", wantErr: true},
+		{name: "standard base64", token: "v4.local.AA+/", wantErr: true},
+		{name: "unsupported header", token: "v2.local.AAAA", wantErr: true},
+		{name: "header only", token: "v4.local.", wantErr: true},
+		{name: "empty footer", token: "v4.local.AAAA.", wantErr: true},
+		{name: "too many segments", token: "v4.local.AAAA.BBBB.CCCC", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := Normalize(testCase.token)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+}
+
+func TestSplit(t *testing.T) {
+	testCases := []struct {
+		name                 string
+		token                string
+		header, body, footer string
+		wantErr              bool
+	}{
+		{name: "without footer", token: "v4.local.AAAA", header: "v4.local.", body: "AAAA"},
+		{name: "with footer", token: "v3.public.AAAA.BBBB", header: "v3.public.", body: "AAAA", footer: "BBBB"},
+		{name: "surrounding whitespaces", token: " v4x.local.AAAA.BBBB\n", header: "v4x.local.", body: "AAAA", footer: "BBBB"},
+		{name: "unsupported header", token: "v2.local.AAAA", wantErr: true},
+		{name: "missing body", token: "v4.local.", wantErr: true},
+		{name: "empty footer", token: "v4.local.AAAA.", wantErr: true},
+		{name: "too many segments", token: "v4.local.AAAA.BBBB.CCCC", wantErr: true},
+		{name: "invalid character", token: "v4.local.AA+A", wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			header, body, footer, err := Split(testCase.token)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.header, header)
+			assert.Equal(t, testCase.body, body)
+			assert.Equal(t, testCase.footer, footer)
+		})
+	}
+}
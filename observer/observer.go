@@ -0,0 +1,65 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package observer provides the hook used by the high-level helpers to report
+// the token operations outcome (metrics, tracing, logging).
+package observer
+
+import "time"
+
+// Operation names reported to the observer.
+const (
+	OpEncrypt = "encrypt"
+	OpDecrypt = "decrypt"
+	OpSign    = "sign"
+	OpVerify  = "verify"
+)
+
+// Observer receives the outcome of each token operation.
+//
+// Implementations must be safe for concurrent use and must not block, they
+// are called synchronously after each operation.
+type Observer interface {
+	// ObserveOp is called once per operation with the protocol version (v3,
+	// v4, v4x), the operation error (nil on success) and its duration.
+	ObserveOp(op, version string, err error, dur time.Duration)
+}
+
+// Func adapts a function to the Observer interface.
+type Func func(op, version string, err error, dur time.Duration)
+
+// ObserveOp calls f(op, version, err, dur).
+func (f Func) ObserveOp(op, version string, err error, dur time.Duration) {
+	f(op, version, err, dur)
+}
+
+// Nop is an observer which discards all observations.
+var Nop Observer = Func(func(string, string, error, time.Duration) {})
+
+// Track runs fn and reports its outcome and duration to the observer. A nil
+// observer is treated as Nop.
+func Track(o Observer, op, version string, fn func() error) error {
+	if o == nil {
+		return fn()
+	}
+
+	start := time.Now()
+	err := fn()
+	o.ObserveOp(op, version, err, time.Since(start))
+
+	return err
+}
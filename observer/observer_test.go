@@ -0,0 +1,59 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package observer
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestTrack(t *testing.T) {
+	type observation struct {
+		op, version string
+		err         error
+		dur         time.Duration
+	}
+
+	var got []observation
+	o := Func(func(op, version string, err error, dur time.Duration) {
+		got = append(got, observation{op: op, version: version, err: err, dur: dur})
+	})
+
+	errFailed := errors.New("failed")
+
+	assert.NoError(t, Track(o, OpEncrypt, "v4", func() error { return nil }))
+	assert.ErrorIs(t, Track(o, OpDecrypt, "v3", func() error { return errFailed }), errFailed)
+
+	assert.Len(t, got, 2)
+	assert.Equal(t, OpEncrypt, got[0].op)
+	assert.Equal(t, "v4", got[0].version)
+	assert.NoError(t, got[0].err)
+	assert.GreaterOrEqual(t, got[0].dur, time.Duration(0))
+	assert.Equal(t, OpDecrypt, got[1].op)
+	assert.Equal(t, "v3", got[1].version)
+	assert.ErrorIs(t, got[1].err, errFailed)
+
+	// Nil and no-op observers
+	called := false
+	assert.NoError(t, Track(nil, OpSign, "v4", func() error { called = true; return nil }))
+	assert.True(t, called)
+	assert.ErrorIs(t, Track(Nop, OpVerify, "v4", func() error { return errFailed }), errFailed)
+}
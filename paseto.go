@@ -0,0 +1,91 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package paseto provides version-agnostic helpers on top of the v3, v4 and
+// v4x PASETO implementations.
+//
+// In all versions, a nil or empty footer is treated as absent: the token has
+// no footer segment (`v4.local.<body>`, never `v4.local.<body>.`), as in the
+// official test vectors.
+package paseto
+
+import (
+	"strings"
+
+	pasetov3 "zntr.io/paseto/v3"
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+// Purpose describes the token purpose.
+type Purpose string
+
+const (
+	// PurposeLocal is used for symmetric encryption.
+	PurposeLocal Purpose = "local"
+	// PurposePublic is used for signatures.
+	PurposePublic Purpose = "public"
+)
+
+// Header describes a supported token header.
+type Header struct {
+	Version string
+	Purpose Purpose
+	Prefix  string
+}
+
+var headers = []Header{
+	{Version: "v3", Purpose: PurposeLocal, Prefix: pasetov3.LocalPrefix},
+	{Version: "v3", Purpose: PurposePublic, Prefix: pasetov3.PublicPrefix},
+	{Version: "v4", Purpose: PurposeLocal, Prefix: pasetov4.LocalPrefix},
+	{Version: "v4", Purpose: PurposePublic, Prefix: pasetov4.PublicPrefix},
+	{Version: "v4x", Purpose: PurposeLocal, Prefix: pasetov4x.LocalPrefix},
+	{Version: "v4x", Purpose: PurposePublic, Prefix: pasetov4x.PublicPrefix},
+}
+
+// Headers returns all supported token headers.
+func Headers() []Header {
+	out := make([]Header, len(headers))
+	copy(out, headers)
+	return out
+}
+
+// LookupHeader returns the header matching the given prefix (`v4.local.`).
+func LookupHeader(prefix string) (Header, bool) {
+	for _, h := range headers {
+		if h.Prefix == prefix {
+			return h, true
+		}
+	}
+
+	return Header{}, false
+}
+
+// HeaderOf returns the header of the given token.
+func HeaderOf(token string) (Header, bool) {
+	// Extract `version.purpose.`
+	versionIdx := strings.IndexByte(token, '.')
+	if versionIdx < 0 {
+		return Header{}, false
+	}
+	purposeIdx := strings.IndexByte(token[versionIdx+1:], '.')
+	if purposeIdx < 0 {
+		return Header{}, false
+	}
+
+	return LookupHeader(token[:versionIdx+purposeIdx+2])
+}
@@ -0,0 +1,269 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pasetov3 "zntr.io/paseto/v3"
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+func TestHeaderOf(t *testing.T) {
+	testCases := []struct {
+		name      string
+		token     string
+		wantFound bool
+		want      Header
+	}{
+		{name: "blank", token: ""},
+		{name: "no purpose", token: "v4"},
+		{name: "unknown", token: "v2.local.AAAA"},
+		{name: "partial", token: "v4.loc"},
+		{name: "v3.local", token: "v3.local.AAAA", wantFound: true, want: Header{Version: "v3", Purpose: PurposeLocal, Prefix: "v3.local."}},
+		{name: "v4.public", token: "v4.public.AAAA.BBBB", wantFound: true, want: Header{Version: "v4", Purpose: PurposePublic, Prefix: "v4.public."}},
+		{name: "v4x.local", token: "v4x.local.AAAA", wantFound: true, want: Header{Version: "v4x", Purpose: PurposeLocal, Prefix: "v4x.local."}},
+		{name: "v4x.public", token: "v4x.public.AAAA", wantFound: true, want: Header{Version: "v4x", Purpose: PurposePublic, Prefix: "v4x.public."}},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			h, found := HeaderOf(testCase.token)
+			assert.Equal(t, testCase.wantFound, found)
+			assert.Equal(t, testCase.want, h)
+		})
+	}
+}
+
+func TestHeaders(t *testing.T) {
+	all := Headers()
+	assert.Len(t, all, 6)
+
+	// Returned slice is a copy
+	all[0].Prefix = "tampered"
+	_, found := LookupHeader("v3.local.")
+	assert.True(t, found)
+}
+
+func TestMissingFooterParity(t *testing.T) {
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+
+	k3, err := pasetov3.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k4x, err := pasetov4x.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	_, sk4, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	sk3, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		open func() error
+	}{
+		{name: "v3.local", open: func() error {
+			token, err := pasetov3.Encrypt(rand.Reader, k3, m, nil, nil)
+			assert.NoError(t, err)
+			_, err = pasetov3.Decrypt(k3, token, f, nil)
+			return err
+		}},
+		{name: "v3.public", open: func() error {
+			// The signature is not checked before the footer
+			_, err := pasetov3.Verify(pasetov3.PublicPrefix+"AAAA", &sk3.PublicKey, f, nil)
+			return err
+		}},
+		{name: "v4.local", open: func() error {
+			token, err := pasetov4.Encrypt(rand.Reader, k4, m, nil, nil)
+			assert.NoError(t, err)
+			_, err = pasetov4.Decrypt(k4, token, f, nil)
+			return err
+		}},
+		{name: "v4.public", open: func() error {
+			token, err := pasetov4.Sign(m, sk4, nil, nil)
+			assert.NoError(t, err)
+			_, err = pasetov4.Verify(token, sk4.Public().(ed25519.PublicKey), f, nil)
+			return err
+		}},
+		{name: "v4x.local", open: func() error {
+			token, err := pasetov4x.Encrypt(rand.Reader, k4x, m, nil, nil)
+			assert.NoError(t, err)
+			_, err = pasetov4x.Decrypt(k4x, token, f, nil)
+			return err
+		}},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			err := testCase.open()
+			assert.ErrorIs(t, err, pasetov4.ErrMissingFooter)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+			assert.EqualError(t, err, "paseto: invalid token, footer is missing but expected")
+		})
+	}
+}
+
+func TestEmptyFooter(t *testing.T) {
+	m := []byte("{\"data\":\"this is a secret message\"}")
+
+	k3, err := pasetov3.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k4x, err := pasetov4x.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	_, sk4, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	sk3, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name  string
+		issue func(f []byte) (string, error)
+	}{
+		{name: "v3.local", issue: func(f []byte) (string, error) { return pasetov3.Encrypt(rand.Reader, k3, m, f, nil) }},
+		{name: "v3.public", issue: func(f []byte) (string, error) { return pasetov3.Sign(m, sk3, f, nil) }},
+		{name: "v4.local", issue: func(f []byte) (string, error) { return pasetov4.Encrypt(rand.Reader, k4, m, f, nil) }},
+		{name: "v4.public", issue: func(f []byte) (string, error) { return pasetov4.Sign(m, sk4, f, nil) }},
+		{name: "v4x.local", issue: func(f []byte) (string, error) { return pasetov4x.Encrypt(rand.Reader, k4x, m, f, nil) }},
+		{name: "v4x.public", issue: func(f []byte) (string, error) { return pasetov4x.Sign(m, sk4, f, nil) }},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			// Empty and nil footers produce no footer segment
+			for _, f := range [][]byte{nil, {}} {
+				token, err := testCase.issue(f)
+				assert.NoError(t, err)
+				assert.Equal(t, 2, strings.Count(token, "."))
+				assert.False(t, strings.HasSuffix(token, "."))
+			}
+		})
+	}
+}
+
+func TestImplicitMismatchHint(t *testing.T) {
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	i := []byte("{\"user_id\":\"1234\"}")
+
+	k3, err := pasetov3.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k4x, err := pasetov4x.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	_, sk4, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pk4 := sk4.Public().(ed25519.PublicKey)
+	sk3, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		open    func(i []byte) error
+		authErr error
+	}{
+		{name: "v3.local", authErr: pasetov3.ErrInvalidMAC, open: func(i []byte) error {
+			token, err := pasetov3.Encrypt(rand.Reader, k3, m, nil, nil)
+			assert.NoError(t, err)
+			_, err = pasetov3.Decrypt(k3, token, nil, i)
+			return err
+		}},
+		{name: "v3.public", authErr: pasetov3.ErrInvalidSignature, open: func(i []byte) error {
+			token, err := pasetov3.Sign(m, sk3, nil, nil)
+			assert.NoError(t, err)
+			_, err = pasetov3.Verify(token, &sk3.PublicKey, nil, i)
+			return err
+		}},
+		{name: "v4.local", authErr: pasetov4.ErrInvalidMAC, open: func(i []byte) error {
+			token, err := pasetov4.Encrypt(rand.Reader, k4, m, nil, nil)
+			assert.NoError(t, err)
+			_, err = pasetov4.Decrypt(k4, token, nil, i)
+			return err
+		}},
+		{name: "v4.public", authErr: pasetov4.ErrInvalidSignature, open: func(i []byte) error {
+			token, err := pasetov4.Sign(m, sk4, nil, nil)
+			assert.NoError(t, err)
+			_, err = pasetov4.Verify(token, pk4, nil, i)
+			return err
+		}},
+		{name: "v4x.local", authErr: pasetov4x.ErrInvalidMAC, open: func(i []byte) error {
+			token, err := pasetov4x.Encrypt(rand.Reader, k4x, m, nil, nil)
+			assert.NoError(t, err)
+			_, err = pasetov4x.Decrypt(k4x, token, nil, i)
+			return err
+		}},
+		{name: "v4x.public", authErr: pasetov4x.ErrInvalidSignature, open: func(i []byte) error {
+			token, err := pasetov4x.Sign(m, sk4, nil, nil)
+			assert.NoError(t, err)
+			_, err = pasetov4x.Verify(token, pk4, nil, i)
+			return err
+		}},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			// Implicit assertion given
+			err := testCase.open(i)
+			assert.ErrorIs(t, err, testCase.authErr)
+			assert.ErrorIs(t, err, pasetov4.ErrPossibleImplicitMismatch)
+
+			// No implicit assertion, no hint
+			err = testCase.open(nil)
+			assert.NoError(t, err)
+		})
+	}
+}
+
+func TestWrongHeader(t *testing.T) {
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	token, err := pasetov4.Encrypt(rand.Reader, k4, []byte("message"), nil, nil)
+	assert.NoError(t, err)
+
+	// v4 token given to the other versions
+	k3, err := pasetov3.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	_, err = pasetov3.Decrypt(k3, token, nil, nil)
+	assert.ErrorIs(t, err, pasetov3.ErrWrongVersion)
+
+	k4x, err := pasetov4x.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	_, err = pasetov4x.Decrypt(k4x, token, nil, nil)
+	assert.ErrorIs(t, err, pasetov4x.ErrWrongVersion)
+
+	// Local token given to the public purpose
+	pk, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, err = pasetov4.Verify(token, pk, nil, nil)
+	assert.ErrorIs(t, err, pasetov4.ErrWrongPurpose)
+}
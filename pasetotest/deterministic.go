@@ -0,0 +1,140 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+// Package pasetotest provides helpers to produce reproducible tokens for
+// snapshot (golden) tests.
+//
+// The keys and the nonces are derived from a seed, so that the produced
+// tokens only depend on the seed and the inputs. The derivation is part of
+// the package contract and will not change.
+//
+// These helpers are NOT SECURE: the keys are derived from a public seed and
+// the nonces are derived from the message. They must never be used outside
+// of tests.
+package pasetotest
+
+import (
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/sha512"
+	"crypto/x509"
+	"fmt"
+	"math/big"
+
+	"zntr.io/paseto/internal/common"
+	pasetov3 "zntr.io/paseto/v3"
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+// Deterministic derives keys and nonces from a seed.
+//
+// The public purposes are already deterministic: v3 uses RFC6979 ECDSA and v4
+// uses Ed25519, sign with the derived private keys using the version
+// packages directly.
+type Deterministic struct {
+	seed []byte
+}
+
+// NewDeterministic returns the helpers for the given seed.
+func NewDeterministic(seed string) *Deterministic {
+	return &Deterministic{seed: []byte(seed)}
+}
+
+// Nonce returns the 32 bytes nonce derived from the seed and the token
+// inputs.
+func (d *Deterministic) Nonce(m, f, i []byte) []byte {
+	return d.derive("nonce", m, f, i)[:32]
+}
+
+// V3LocalKey returns the v3 local key derived from the seed.
+func (d *Deterministic) V3LocalKey() *pasetov3.LocalKey {
+	var key pasetov3.LocalKey
+	copy(key[:], d.derive("v3.local"))
+	return &key
+}
+
+// V3PrivateKey returns the v3 (P-384) private key derived from the seed.
+func (d *Deterministic) V3PrivateKey() *ecdsa.PrivateKey {
+	// Map the derived value to [1, n-1]
+	n1 := new(big.Int).Sub(elliptic.P384().Params().N, big.NewInt(1))
+	k := new(big.Int).SetBytes(d.derive("v3.public"))
+	k.Mod(k, n1).Add(k, big.NewInt(1))
+
+	// Build the key
+	sk, err := ecdh.P384().NewPrivateKey(k.FillBytes(make([]byte, 48)))
+	if err != nil {
+		panic(fmt.Errorf("pasetotest: unable to derive the v3 private key: %w", err))
+	}
+
+	// Convert to ECDSA
+	der, err := x509.MarshalPKCS8PrivateKey(sk)
+	if err != nil {
+		panic(fmt.Errorf("pasetotest: unable to encode the v3 private key: %w", err))
+	}
+	key, err := x509.ParsePKCS8PrivateKey(der)
+	if err != nil {
+		panic(fmt.Errorf("pasetotest: unable to decode the v3 private key: %w", err))
+	}
+
+	return key.(*ecdsa.PrivateKey)
+}
+
+// V4LocalKey returns the v4 local key derived from the seed.
+func (d *Deterministic) V4LocalKey() *pasetov4.LocalKey {
+	var key pasetov4.LocalKey
+	copy(key[:], d.derive("v4.local"))
+	return &key
+}
+
+// V4PrivateKey returns the v4 (Ed25519) private key derived from the seed.
+func (d *Deterministic) V4PrivateKey() ed25519.PrivateKey {
+	return ed25519.NewKeyFromSeed(d.derive("v4.public")[:ed25519.SeedSize])
+}
+
+// V4xLocalKey returns the v4x local key derived from the seed.
+func (d *Deterministic) V4xLocalKey() *pasetov4x.LocalKey {
+	var key pasetov4x.LocalKey
+	copy(key[:], d.derive("v4x.local"))
+	return &key
+}
+
+// V3Encrypt encrypts the message with the derived nonce.
+func (d *Deterministic) V3Encrypt(key *pasetov3.LocalKey, m, f, i []byte) (string, error) {
+	return pasetov3.EncryptWithNonce(key, d.Nonce(m, f, i), m, f, i)
+}
+
+// V4Encrypt encrypts the message with the derived nonce.
+func (d *Deterministic) V4Encrypt(key *pasetov4.LocalKey, m, f, i []byte) (string, error) {
+	return pasetov4.EncryptWithNonce(key, d.Nonce(m, f, i), m, f, i)
+}
+
+// V4xEncrypt encrypts the message with the derived nonce.
+func (d *Deterministic) V4xEncrypt(key *pasetov4x.LocalKey, m, f, i []byte) (string, error) {
+	return pasetov4x.EncryptWithNonce(key, d.Nonce(m, f, i), m, f, i)
+}
+
+// -----------------------------------------------------------------------------
+
+// derive returns SHA-384(PAE("pasetotest", seed, label, parts...)).
+func (d *Deterministic) derive(label string, parts ...[]byte) []byte {
+	pieces := append([][]byte{[]byte("pasetotest"), d.seed, []byte(label)}, parts...)
+	h := sha512.Sum384(common.PreAuthenticationEncoding(pieces...))
+	return h[:]
+}
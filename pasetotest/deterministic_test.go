@@ -0,0 +1,93 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package pasetotest
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pasetov3 "zntr.io/paseto/v3"
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+// Golden tokens, they must never change.
+const (
+	goldenV3Local  = "v3.local.r38vO1M7YupMFZf2lpma39HME6aVKlp-y_ENXP34Yd_rHdVtBhxM9Ha1iHn9FVt8JQ16XvVBVzKiMJvdqnwb2AcVcIItmZE_RyFgSg1_5NwBzfCB-997Yo3VQDxHJ592LtQ.eyJraWQiOiIxMjM0In0"
+	goldenV3Public = "v3.public.eyJzdWIiOiJ1c2VyLTEyMyJ9lDBRbHy2emcpPIFNlVX0dWU5flU070GChLYfA1uLFak-UX1cL3eGUEsotg7RIlSPtay9PFYIDgW3oGLV5JUfTaWlOhYWb4bu8cj7Xy39-R4lDBjdfs_FxP2YvlNrCFu7.eyJraWQiOiIxMjM0In0"
+	goldenV4Local  = "v4.local.r38vO1M7YupMFZf2lpma39HME6aVKlp-y_ENXP34Yd9b2q4jrA8ZUXfRRrEXZ0cxit1ljOH5TlF2CYkpsEh85RZh71DAVU8eRsz9u4idRR33Ig.eyJraWQiOiIxMjM0In0"
+	goldenV4Public = "v4.public.eyJzdWIiOiJ1c2VyLTEyMyJ9bVLYcBIuzLVEmVb3MNqlmyut5oIXX46elXo4Mh66VQqs_sCV9fv3pq2752wYpAw7pdzpRSVqgnDNv889at-AAQ.eyJraWQiOiIxMjM0In0"
+	goldenV4xLocal = "v4x.local.r38vO1M7YupMFZf2lpma39HME6aVKlp-y_ENXP34Yd_fLGBuih68IBzeEc2bEGBMsL5y1eeyGyKh0oQnBNRYDsWmrRbXYMfYWtl5urYqGzRiRQ.eyJraWQiOiIxMjM0In0"
+)
+
+func TestDeterministic_Golden(t *testing.T) {
+	d := NewDeterministic("golden")
+
+	m := []byte(`{"sub":"user-123"}`)
+	f := []byte(`{"kid":"1234"}`)
+	i := []byte("implicit")
+
+	// Run twice to check the stability across calls
+	for run := 0; run < 2; run++ {
+		token, err := d.V3Encrypt(d.V3LocalKey(), m, f, i)
+		assert.NoError(t, err)
+		assert.Equal(t, goldenV3Local, token)
+
+		token, err = pasetov3.Sign(m, d.V3PrivateKey(), f, i)
+		assert.NoError(t, err)
+		assert.Equal(t, goldenV3Public, token)
+
+		token, err = d.V4Encrypt(d.V4LocalKey(), m, f, i)
+		assert.NoError(t, err)
+		assert.Equal(t, goldenV4Local, token)
+
+		token, err = pasetov4.Sign(m, d.V4PrivateKey(), f, i)
+		assert.NoError(t, err)
+		assert.Equal(t, goldenV4Public, token)
+
+		token, err = d.V4xEncrypt(d.V4xLocalKey(), m, f, i)
+		assert.NoError(t, err)
+		assert.Equal(t, goldenV4xLocal, token)
+	}
+
+	// Golden tokens are valid
+	p, err := pasetov3.Decrypt(d.V3LocalKey(), goldenV3Local, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+	p, err = pasetov3.Verify(goldenV3Public, &d.V3PrivateKey().PublicKey, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+	p, err = pasetov4.Decrypt(d.V4LocalKey(), goldenV4Local, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+	p, err = pasetov4x.Decrypt(d.V4xLocalKey(), goldenV4xLocal, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+}
+
+func TestDeterministic_Seeds(t *testing.T) {
+	a := NewDeterministic("a")
+	b := NewDeterministic("b")
+
+	assert.False(t, a.V4LocalKey().Equal(b.V4LocalKey()))
+	assert.False(t, a.V4PrivateKey().Equal(b.V4PrivateKey()))
+	assert.False(t, a.V3PrivateKey().Equal(b.V3PrivateKey()))
+	assert.NotEqual(t, a.Nonce([]byte("m"), nil, nil), a.Nonce([]byte("n"), nil, nil))
+	assert.Len(t, a.Nonce(nil, nil, nil), 32)
+}
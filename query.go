@@ -0,0 +1,57 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"fmt"
+	"net/url"
+)
+
+// MaxQueryParamLength is the recommended maximum token length to embed in a
+// URL. Browsers, proxies and servers commonly limit URLs to a few kilobytes,
+// a token embedded in a link (magic links) should stay small: avoid large
+// footers and claims, or pass a reference to the token instead.
+const MaxQueryParamLength = 2000
+
+// ToQueryParam returns the token encoded as a query parameter value.
+//
+// Valid tokens only contain base64url characters and dots which are not
+// escaped, the value is escaped anyway so that an invalid input can't inject
+// other parameters.
+func ToQueryParam(token string) string {
+	return url.QueryEscape(token)
+}
+
+// FromQueryParam decodes a query parameter value produced by ToQueryParam and
+// checks the token structure with Normalize. Percent-encoded characters (some
+// clients escape the dots) are decoded.
+func FromQueryParam(value string) (string, error) {
+	// Unescape the value
+	token, err := url.QueryUnescape(value)
+	if err != nil {
+		return "", fmt.Errorf("%w, invalid query parameter encoding: %v", ErrInvalidToken, err)
+	}
+
+	// Check length
+	if len(token) > MaxQueryParamLength {
+		return "", fmt.Errorf("%w, token is too large for a query parameter", ErrInvalidToken)
+	}
+
+	// No error
+	return Normalize(token)
+}
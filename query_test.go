@@ -0,0 +1,75 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/rand"
+	"net/url"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pasetov4 "zntr.io/paseto/v4"
+)
+
+func TestQueryParam(t *testing.T) {
+	key, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	token, err := pasetov4.Encrypt(rand.Reader, key, []byte(`{"sub":"user-1"}`), []byte(`{"kid":"1234"}`), nil)
+	assert.NoError(t, err)
+
+	// Tokens are not escaped
+	value := ToQueryParam(token)
+	assert.Equal(t, token, value)
+
+	// Round trip through an URL
+	u, err := url.Parse("https://example.com/login?token=" + value + "&next=%2F")
+	assert.NoError(t, err)
+	got, err := FromQueryParam(u.Query().Get("token"))
+	assert.NoError(t, err)
+	assert.Equal(t, token, got)
+
+	testCases := []struct {
+		name    string
+		value   string
+		want    string
+		wantErr bool
+	}{
+		{name: "escaped dots", value: strings.ReplaceAll(token, ".", "%2E"), want: token},
+		{name: "invalid escape", value: "v4.local.AAAA%zz", wantErr: true},
+		{name: "injected parameter", value: "v4.local.AAAA&admin=1", wantErr: true},
+		{name: "blank", value: "", wantErr: true},
+		{name: "too large", value: "v4.local." + strings.Repeat("A", MaxQueryParamLength), wantErr: true},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := FromQueryParam(testCase.value)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, ErrInvalidToken)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+
+	// Invalid inputs can't inject parameters
+	assert.Equal(t, "v4.local.AAAA%26admin%3D1", ToQueryParam("v4.local.AAAA&admin=1"))
+}
@@ -0,0 +1,65 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+
+	"zntr.io/paseto/claims"
+)
+
+// RedactedInvalidToken is the loggable form of a malformed token.
+const RedactedInvalidToken = "[invalid token]"
+
+// Redact returns a loggable form of the given token.
+//
+// The header and the footer key identifier are kept, the body is replaced by
+// a truncated SHA-256 fingerprint of the serialized body so that log lines
+// referring to the same token can be correlated without leaking the payload
+// (local) or a replayable token (public). The rest of the footer is dropped.
+//
+//	v4.local.[sha256:4b7c15e9a2d1c3f0] kid="k4.lid.xxx"
+//
+// A malformed token is never echoed, RedactedInvalidToken is returned.
+func Redact(token string) string {
+	// Check token structure
+	prefix, rawBody, rawFooter, err := Split(token)
+	if err != nil {
+		return RedactedInvalidToken
+	}
+
+	// Fingerprint the body
+	sum := sha256.Sum256([]byte(rawBody))
+	out := prefix + "[sha256:" + hex.EncodeToString(sum[:8]) + "]"
+
+	// Keep the footer key identifier (quoted to prevent log injection)
+	if len(rawFooter) > 0 {
+		var f claims.Footer
+		footer, err := base64.RawURLEncoding.DecodeString(rawFooter)
+		if err == nil && json.Unmarshal(footer, &f) == nil && f.KeyID != "" {
+			out += " kid=" + strconv.Quote(f.KeyID)
+		}
+	}
+
+	// No error
+	return out
+}
@@ -0,0 +1,48 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRedact(t *testing.T) {
+	testCases := []struct {
+		name  string
+		token string
+		want  string
+	}{
+		{name: "no footer", token: "v4.local.AAAA", want: "v4.local.[sha256:63c1dd951ffedf6f]"},
+		{name: "kid footer", token: "v4.public.AAAA.eyJraWQiOiIxMjM0In0", want: "v4.public.[sha256:63c1dd951ffedf6f] kid=\"1234\""},
+		{name: "quoted kid", token: "v3.local.AAAA.eyJraWQiOiJhXCJcbmIifQ", want: "v3.local.[sha256:63c1dd951ffedf6f] kid=\"a\\\"\\nb\""},
+		{name: "non-JSON footer", token: "v4.local.AAAA.QkJCQg", want: "v4.local.[sha256:63c1dd951ffedf6f]"},
+		{name: "surrounding whitespaces", token: " v4.local.AAAA\n", want: "v4.local.[sha256:63c1dd951ffedf6f]"},
+		{name: "unsupported version", token: "v2.local.AAAA", want: RedactedInvalidToken},
+		{name: "empty body", token: "v4.local.", want: RedactedInvalidToken},
+		{name: "blank", token: "", want: RedactedInvalidToken},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.want, Redact(testCase.token))
+		})
+	}
+}
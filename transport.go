@@ -0,0 +1,114 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Encoder encodes the token segments for a transport. *base64.Encoding and
+// *base32.Encoding implement it.
+type Encoder interface {
+	EncodeToString(src []byte) string
+	DecodeString(s string) ([]byte, error)
+}
+
+// EncodeForTransport re-encodes the body and the footer of the token with the
+// given encoder, the header and the segment separators are kept.
+//
+// NON STANDARD: the result is not a PASETO token, it is intended for closed
+// ecosystems whose transport mangles the base64url characters (barcodes,
+// legacy channels). The receiver must restore the token with
+// DecodeFromTransport and the same encoder before processing it. The
+// cryptographic content is unchanged.
+func EncodeForTransport(token string, enc Encoder) (string, error) {
+	// Check arguments
+	if enc == nil {
+		return "", errors.New("paseto: encoder must not be nil")
+	}
+
+	return transcode(token, base64.RawURLEncoding, enc)
+}
+
+// DecodeFromTransport restores a token encoded with EncodeForTransport and the
+// same encoder, and checks its structure with Normalize.
+func DecodeFromTransport(value string, enc Encoder) (string, error) {
+	// Check arguments
+	if enc == nil {
+		return "", errors.New("paseto: encoder must not be nil")
+	}
+
+	// Restore standard encoding
+	token, err := transcode(value, enc, base64.RawURLEncoding)
+	if err != nil {
+		return "", err
+	}
+
+	// No error
+	return Normalize(token)
+}
+
+// -----------------------------------------------------------------------------
+
+// transcode decodes the body and the footer with `from` and encodes them with
+// `to`.
+func transcode(token string, from, to Encoder) (string, error) {
+	// Check header
+	token = strings.TrimSpace(token)
+	h, ok := HeaderOf(token)
+	if !ok {
+		return "", fmt.Errorf("%w, unsupported header", ErrInvalidToken)
+	}
+
+	// Check segments
+	segments := strings.Split(token[len(h.Prefix):], ".")
+	if len(segments) > 2 {
+		return "", fmt.Errorf("%w, too many segments", ErrInvalidToken)
+	}
+
+	var sb strings.Builder
+	sb.WriteString(h.Prefix)
+	for j, segment := range segments {
+		if segment == "" {
+			return "", fmt.Errorf("%w, empty segment", ErrInvalidToken)
+		}
+
+		// Decode the segment
+		raw, err := from.DecodeString(segment)
+		if err != nil {
+			return "", fmt.Errorf("%w, invalid segment encoding: %v", ErrInvalidToken, err)
+		}
+
+		// Encode the segment
+		encoded := to.EncodeToString(raw)
+		if strings.ContainsRune(encoded, '.') {
+			return "", errors.New("paseto: encoder alphabet must not contain the segment separator")
+		}
+
+		if j > 0 {
+			sb.WriteByte('.')
+		}
+		sb.WriteString(encoded)
+	}
+
+	// No error
+	return sb.String(), nil
+}
@@ -0,0 +1,82 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/rand"
+	"encoding/base32"
+	"encoding/base64"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pasetov4 "zntr.io/paseto/v4"
+)
+
+func TestTransportEncoding(t *testing.T) {
+	key, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("payload")
+	f := []byte("{\"kid\":\"1234\"}")
+
+	token, err := pasetov4.Encrypt(rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		enc  Encoder
+	}{
+		{name: "base32", enc: base32.StdEncoding.WithPadding(base32.NoPadding)},
+		{name: "base64 standard", enc: base64.RawStdEncoding},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			encoded, err := EncodeForTransport(token, testCase.enc)
+			assert.NoError(t, err)
+			assert.True(t, strings.HasPrefix(encoded, pasetov4.LocalPrefix))
+			assert.False(t, strings.ContainsAny(encoded, "-_"))
+
+			decoded, err := DecodeFromTransport(encoded, testCase.enc)
+			assert.NoError(t, err)
+			assert.Equal(t, token, decoded)
+
+			p, err := pasetov4.Decrypt(key, decoded, f, nil)
+			assert.NoError(t, err)
+			assert.Equal(t, m, p)
+		})
+	}
+
+	// Invalid inputs
+	enc := base32.StdEncoding.WithPadding(base32.NoPadding)
+	_, err = EncodeForTransport(token, nil)
+	assert.Error(t, err)
+	_, err = DecodeFromTransport(token, nil)
+	assert.Error(t, err)
+	_, err = EncodeForTransport("v2.local.AAAA", enc)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = EncodeForTransport("v4.local.AAAA.BBBB.CCCC", enc)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = EncodeForTransport("v4.local.AAAA.", enc)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = DecodeFromTransport("v4.local.!!!!", enc)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
@@ -18,29 +18,22 @@
 package v3
 
 import (
-	"crypto/hmac"
 	"crypto/sha512"
 	"errors"
 	"fmt"
-	"io"
-
-	"golang.org/x/crypto/hkdf"
 
 	"zntr.io/paseto/internal/common"
 )
 
-func kdf(key *LocalKey, n []byte) (ek, n2, ak []byte, err error) {
+func kdf(p MACProvider, key *LocalKey, n, salt []byte) (ek, n2, ak []byte, err error) {
 	// Check arguments
 	if key == nil {
 		return nil, nil, nil, errors.New("unable to derive keys from a nil seed")
 	}
 
-	// Prepare HKDF-HMAC-SHA384
-	encKDF := hkdf.New(sha512.New384, key[:], nil, append([]byte("paseto-encryption-key"), n...))
-
-	// Derive encryption key
-	tmp := make([]byte, kdfOutputLength)
-	if _, err := io.ReadFull(encKDF, tmp); err != nil {
+	// Derive encryption key with HKDF-HMAC-SHA384
+	tmp, err := hkdf(p, key[:], salt, append([]byte("paseto-encryption-key"), n...))
+	if err != nil {
 		return nil, nil, nil, fmt.Errorf("unable to generate encryption key from seed: %w", err)
 	}
 
@@ -49,11 +42,8 @@ func kdf(key *LocalKey, n []byte) (ek, n2, ak []byte, err error) {
 	n2 = tmp[KeyLength:]
 
 	// Derive authentication key
-	authKDF := hkdf.New(sha512.New384, key[:], nil, append([]byte("paseto-auth-key-for-aead"), n...))
-
-	// Derive authentication key
-	ak = make([]byte, kdfOutputLength)
-	if _, err := io.ReadFull(authKDF, ak); err != nil {
+	ak, err = hkdf(p, key[:], salt, append([]byte("paseto-auth-key-for-aead"), n...))
+	if err != nil {
 		return nil, nil, nil, fmt.Errorf("unable to generate authentication key from seed: %w", err)
 	}
 
@@ -61,16 +51,46 @@ func kdf(key *LocalKey, n []byte) (ek, n2, ak []byte, err error) {
 	return ek, n2, ak, nil
 }
 
-func mac(ak, h, n, c, f, i []byte) []byte {
+// hkdf implements RFC5869 HKDF-HMAC-SHA384 with the given MAC provider, for
+// a kdfOutputLength (one hash block) output.
+func hkdf(p MACProvider, secret, salt, info []byte) ([]byte, error) {
+	// Use a zero-filled salt by default
+	if len(salt) == 0 {
+		salt = make([]byte, sha512.Size384)
+	}
+
+	// Extract
+	prk, err := p.HMACSHA384(salt, secret)
+	if err != nil {
+		return nil, err
+	}
+
+	// Expand the first block only
+	okm, err := p.HMACSHA384(prk, append(info[:len(info):len(info)], 0x01))
+	if err != nil {
+		return nil, err
+	}
+	if len(okm) != kdfOutputLength {
+		return nil, errors.New("invalid MAC length")
+	}
+
+	// No error
+	return okm, nil
+}
+
+func mac(p MACProvider, ak, h, n, c, f, i []byte) ([]byte, error) {
 	// Compute pre-authentication message
 	preAuth := common.PreAuthenticationEncoding(h, n, c, f, i)
 
 	// Compute MAC
-	mac := hmac.New(sha512.New384, ak)
-
-	// Hash pre-authentication content
-	mac.Write(preAuth)
+	t, err := p.HMACSHA384(ak, preAuth)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to compute MAC: %w", err)
+	}
+	if len(t) != macLength {
+		return nil, errors.New("paseto: unable to compute MAC: invalid MAC length")
+	}
 
 	// No error
-	return mac.Sum(nil)
+	return t, nil
 }
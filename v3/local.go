@@ -18,7 +18,6 @@
 package v3
 
 import (
-	"bytes"
 	"crypto/aes"
 	"crypto/cipher"
 	"crypto/subtle"
@@ -26,12 +25,17 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"strings"
+
+	"zntr.io/paseto/internal/common"
 )
 
 // GenerateLocalKey generates a key for local encryption.
+//
+// It reads exactly KeyLength bytes from r.
 func GenerateLocalKey(r io.Reader) (*LocalKey, error) {
 	var key LocalKey
-	if _, err := io.ReadFull(r, key[:]); err != nil {
+	if err := common.ReadRandom(r, key[:]); err != nil {
 		return nil, fmt.Errorf("paseto: unable to generate a random key: %w", err)
 	}
 
@@ -43,7 +47,7 @@ func GenerateLocalKey(r io.Reader) (*LocalKey, error) {
 func LocalKeyFromSeed(seed []byte) (*LocalKey, error) {
 	// Check minimum seed size.
 	if len(seed) < KeyLength {
-		return nil, fmt.Errorf("paseto: invalid seed length, it must be %d bytes long at least", KeyLength)
+		return nil, fmt.Errorf("%w, seed must be %d bytes long at least", ErrInvalidKeyLength, KeyLength)
 	}
 
 	// Copy data from seed.
@@ -54,27 +58,109 @@ func LocalKeyFromSeed(seed []byte) (*LocalKey, error) {
 	return &key, nil
 }
 
+// LocalKeyFromHex creates a local key from its hex encoded form. The decoded
+// key must be exactly KeyLength bytes long.
+func LocalKeyFromHex(s string) (*LocalKey, error) {
+	raw, err := common.DecodeHexKey(s, KeyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return LocalKeyFromSeed(raw)
+}
+
+// LocalKeyFromBase64 creates a local key from its base64 encoded form, with
+// the standard or URL-safe alphabet, padded or not. The decoded key must be
+// exactly KeyLength bytes long.
+func LocalKeyFromBase64(s string) (*LocalKey, error) {
+	raw, err := common.DecodeBase64Key(s, KeyLength)
+	if err != nil {
+		return nil, err
+	}
+
+	return LocalKeyFromSeed(raw)
+}
+
 // PASETO v3 symmetric encryption primitive.
 // https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version3.md#encrypt
+//
+// It reads exactly 32 bytes (the nonce) from r.
 func Encrypt(r io.Reader, key *LocalKey, m, f, i []byte) (string, error) {
+	n, err := readNonce(r)
+	if err != nil {
+		return "", err
+	}
+
+	return encrypt(n, key, m, f, i, nil, stdMAC{})
+}
+
+// EncryptWithNonce encrypts the message (m) using the given nonce instead of a
+// random one.
+//
+// It is intended for testing and conformance checks against the published test
+// vectors only. Reusing a nonce breaks the confidentiality of the tokens, it
+// must never be used in production code.
+func EncryptWithNonce(key *LocalKey, nonce, m, f, i []byte) (string, error) {
+	return encrypt(nonce, key, m, f, i, nil, stdMAC{})
+}
+
+// PASETO v3 symmetric decryption primitive.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version3.md#decrypt
+func Decrypt(key *LocalKey, token string, f, i []byte) ([]byte, error) {
+	return decrypt(key, token, f, i, nil, stdMAC{})
+}
+
+// DecryptWithKDFSalt decrypts a token like Decrypt but derives the keys with
+// the given HKDF salt.
+//
+// NON STANDARD: PASETO v3 derives the keys with an empty salt. This is a
+// migration bridge to read tokens produced by a misconfigured implementation
+// with a fixed salt only, tokens must be re-encrypted with Encrypt.
+func DecryptWithKDFSalt(key *LocalKey, token string, f, i, salt []byte) ([]byte, error) {
+	// Check arguments
+	if len(salt) == 0 {
+		return nil, errors.New("paseto: salt must not be blank, use Decrypt")
+	}
+
+	return decrypt(key, token, f, i, salt, stdMAC{})
+}
+
+// IsLocal returns true when the token has the `v3.local.` header. It only checks
+// the header, the token is neither decoded nor authenticated.
+func IsLocal(token string) bool {
+	return strings.HasPrefix(token, LocalPrefix)
+}
+
+// -----------------------------------------------------------------------------
+
+func readNonce(r io.Reader) ([]byte, error) {
+	n := make([]byte, nonceLength)
+	if err := common.ReadRandom(r, n); err != nil {
+		return nil, fmt.Errorf("paseto: unable to generate random seed: %w", err)
+	}
+
+	// No error
+	return n, nil
+}
+
+func encrypt(nonce []byte, key *LocalKey, m, f, i, salt []byte, p MACProvider) (string, error) {
 	// Check arguments
 	if key == nil {
-		return "", errors.New("paseto: key is nil")
+		return "", ErrNilKey
 	}
 	if len(key) != KeyLength {
-		return "", fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+		return "", fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, KeyLength)
+	}
+	if len(nonce) != nonceLength {
+		return "", fmt.Errorf("paseto: invalid nonce length, it must be %d bytes long", nonceLength)
 	}
 
 	// Pre-allocate body
 	body := make([]byte, nonceLength+len(m), nonceLength+len(m)+macLength)
-
-	// Create random seed
-	if _, err := io.ReadFull(r, body[:nonceLength]); err != nil {
-		return "", fmt.Errorf("paseto: unable to generate random seed: %w", err)
-	}
+	copy(body, nonce)
 
 	// Derive keys from seed and secret key
-	ek, n2, ak, err := kdf(key, body[:nonceLength])
+	ek, n2, ak, err := kdf(p, key, body[:nonceLength], salt)
 	if err != nil {
 		return "", fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
 	}
@@ -90,7 +176,10 @@ func Encrypt(r io.Reader, key *LocalKey, m, f, i []byte) (string, error) {
 	ciph.XORKeyStream(body[nonceLength:], m)
 
 	// Compute MAC
-	t := mac(ak, []byte(LocalPrefix), body[:nonceLength], body[nonceLength:], f, i)
+	t, err := mac(p, ak, []byte(LocalPrefix), body[:nonceLength], body[nonceLength:], f, i)
+	if err != nil {
+		return "", err
+	}
 
 	// Serialize final token
 	// h || base64url(n || c || t)
@@ -118,15 +207,13 @@ func Encrypt(r io.Reader, key *LocalKey, m, f, i []byte) (string, error) {
 	return string(final), nil
 }
 
-// PASETO v3 symmetric decryption primitive.
-// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version3.md#decrypt
-func Decrypt(key *LocalKey, token string, f, i []byte) ([]byte, error) {
+func decrypt(key *LocalKey, token string, f, i, salt []byte, p MACProvider) ([]byte, error) {
 	// Check arguments
 	if key == nil {
-		return nil, errors.New("paseto: key is nil")
+		return nil, ErrNilKey
 	}
 	if len(key) != KeyLength {
-		return nil, fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, KeyLength)
 	}
 	if token == "" {
 		return nil, errors.New("paseto: token is blank")
@@ -135,59 +222,69 @@ func Decrypt(key *LocalKey, token string, f, i []byte) ([]byte, error) {
 	rawToken := []byte(token)
 
 	// Check token header
-	if !bytes.HasPrefix(rawToken, []byte(LocalPrefix)) {
-		return nil, errors.New("paseto: invalid token")
+	if err := common.CheckHeader(rawToken, LocalPrefix); err != nil {
+		return nil, err
 	}
 
 	// Trim prefix
 	rawToken = rawToken[len(LocalPrefix):]
 
+	// Split the footer and the body
+	rawBody, rawFooter, err := common.SplitToken(rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check footer size before decoding
+	if err := common.CheckFooterLength(rawFooter, MaxFooterLength); err != nil {
+		return nil, err
+	}
+
 	// Check footer usage
 	if len(f) > 0 {
-		// Split the footer and the body
-		footerIdx := bytes.Index(rawToken, []byte("."))
-		if footerIdx == 0 {
-			return nil, errors.New("paseto: invalid token, footer is missing but expected")
+		if len(rawFooter) == 0 {
+			return nil, ErrMissingFooter
 		}
 
 		// Decode footer
-		footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawToken[footerIdx+1:])))
-		if _, err := base64.RawURLEncoding.Decode(footer, rawToken[footerIdx+1:]); err != nil {
-			return nil, fmt.Errorf("paseto: invalid token, footer has invalid encoding: %w", err)
+		footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawFooter)))
+		if _, err := base64.RawURLEncoding.Decode(footer, rawFooter); err != nil {
+			return nil, fmt.Errorf("%w, footer has invalid encoding: %v", ErrInvalidToken, err)
 		}
 
 		// Compare footer
 		if subtle.ConstantTimeCompare(f, footer) == 0 {
-			return nil, errors.New("paseto: invalid token, footer mismatch")
+			return nil, fmt.Errorf("%w, footer mismatch", ErrInvalidToken)
 		}
-
-		// Continue without footer
-		rawToken = rawToken[:footerIdx]
 	}
 
 	// Decode token
-	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawToken)))
-	if _, err := base64.RawURLEncoding.Decode(raw, rawToken); err != nil {
-		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
+	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawBody)))
+	if _, err := base64.RawURLEncoding.Decode(raw, rawBody); err != nil {
+		return nil, fmt.Errorf("%w body: %v", ErrInvalidToken, err)
 	}
 
 	// Extract components
-	n := raw[:nonceLength]
-	t := raw[len(raw)-macLength:]
-	c := raw[nonceLength : len(raw)-macLength]
+	n, c, t, err := common.SplitLocalBody(raw, nonceLength, macLength)
+	if err != nil {
+		return nil, err
+	}
 
 	// Derive keys from seed and secret key
-	ek, n2, ak, err := kdf(key, n)
+	ek, n2, ak, err := kdf(p, key, n, salt)
 	if err != nil {
 		return nil, fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
 	}
 
 	// Compute MAC
-	t2 := mac(ak, []byte(LocalPrefix), n, c, f, i)
+	t2, err := mac(p, ak, []byte(LocalPrefix), n, c, f, i)
+	if err != nil {
+		return nil, err
+	}
 
 	// Time-constant compare MAC
 	if subtle.ConstantTimeCompare(t, t2) == 0 {
-		return nil, errors.New("paseto: invalid pre-authentication header")
+		return nil, common.AuthError(ErrInvalidMAC, i)
 	}
 
 	// Prepare an AES-256-CTR stream cipher
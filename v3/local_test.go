@@ -20,8 +20,12 @@ package v3
 import (
 	"bytes"
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -146,7 +150,7 @@ func Test_Paseto_LocalVector(t *testing.T) {
 			assert.NoError(t, err)
 
 			// Encrypt
-			token, err := Encrypt(bytes.NewReader(n), key, []byte(testCase.payload), testCase.footer, testCase.implicitAssertion)
+			token, err := EncryptWithNonce(key, n, []byte(testCase.payload), testCase.footer, testCase.implicitAssertion)
 			if (err != nil) != testCase.expectFail {
 				t.Errorf("error during the encrypt call, error = %v, wantErr %v", err, testCase.expectFail)
 				return
@@ -190,6 +194,337 @@ func Test_Paseto_Local_EncryptDecrypt(t *testing.T) {
 	assert.Equal(t, m, p)
 }
 
+func Test_Paseto_Local_FooterSegments(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+
+	withFooter, err := Encrypt(rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+	withoutFooter, err := Encrypt(rand.Reader, key, m, nil, nil)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		token  string
+		footer []byte
+	}{
+		{name: "extra segment", token: withFooter + ".Zm9vdGVy", footer: f},
+		{name: "trailing dot", token: withFooter + ".", footer: f},
+		{name: "empty footer segment", token: withoutFooter + ".", footer: nil},
+		{name: "missing footer", token: withoutFooter, footer: f},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Decrypt(key, testCase.token, testCase.footer, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_Paseto_Local_KeyErrors(t *testing.T) {
+	_, err := Encrypt(rand.Reader, nil, []byte("message"), nil, nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+
+	_, err = Decrypt(nil, LocalPrefix+"AAAA", nil, nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+
+	_, err = LocalKeyFromSeed(make([]byte, KeyLength-1))
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+}
+
+func Test_Paseto_Local_EmptyMessage(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"heartbeat\"}")
+
+	for _, m := range [][]byte{nil, {}} {
+		for _, footer := range [][]byte{nil, f} {
+			token, err := Encrypt(rand.Reader, key, m, footer, i)
+			assert.NoError(t, err)
+
+			p, err := Decrypt(key, token, footer, i)
+			assert.NoError(t, err)
+			assert.Empty(t, p)
+		}
+	}
+}
+
+func Test_Paseto_Local_MalformedToken(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+
+	token, err := Encrypt(rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+
+	body := strings.Split(strings.TrimPrefix(token, LocalPrefix), ".")[0]
+	footer := strings.Split(token, ".")[3]
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{name: "wrong header", token: "v2.local." + body + "." + footer},
+		{name: "truncated body", token: LocalPrefix + body[:10] + "." + footer},
+		{name: "truncated body with invalid length", token: LocalPrefix + body[:4*(len(body)/8)+1] + "." + footer},
+		{name: "invalid body encoding", token: LocalPrefix + "!" + body[1:] + "." + footer},
+		{name: "invalid footer encoding", token: LocalPrefix + body + "." + footer + "!"},
+		{name: "empty footer", token: LocalPrefix + body + "."},
+		{name: "too many segments", token: token + ".extra"},
+		{name: "leading whitespace", token: " " + token},
+		{name: "trailing whitespace", token: token + " "},
+		{name: "trailing newline", token: token + "\n"},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Decrypt(key, testCase.token, f, nil)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func Test_Paseto_Local_ShortBody(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Valid base64 bodies shorter than the nonce and the MAC
+	for n := 1; n < nonceLength+macLength; n++ {
+		token := LocalPrefix + base64.RawURLEncoding.EncodeToString(make([]byte, n))
+		assert.NotPanics(t, func() {
+			_, err := Decrypt(key, token, nil, nil)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	// Minimal length is structurally valid
+	token := LocalPrefix + base64.RawURLEncoding.EncodeToString(make([]byte, nonceLength+macLength))
+	_, err = Decrypt(key, token, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+}
+
+func Test_Paseto_Local_MACOnlyBody(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	token, err := Encrypt(rand.Reader, key, []byte("message"), nil, nil)
+	assert.NoError(t, err)
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, LocalPrefix))
+	assert.NoError(t, err)
+
+	// Body made of the MAC only (no nonce)
+	macOnly := LocalPrefix + base64.RawURLEncoding.EncodeToString(raw[len(raw)-macLength:])
+	_, err = Decrypt(key, macOnly, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.NotErrorIs(t, err, ErrInvalidMAC)
+
+	// Body missing a single byte of the nonce
+	truncated := LocalPrefix + base64.RawURLEncoding.EncodeToString(raw[len(raw)-macLength-nonceLength+1:])
+	_, err = Decrypt(key, truncated, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func Test_Paseto_Local_ShortRandom(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Short random source
+	_, err = Encrypt(bytes.NewReader(make([]byte, nonceLength-1)), key, []byte("payload"), nil, nil)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = GenerateLocalKey(bytes.NewReader(make([]byte, KeyLength-1)))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// Random source without progress
+	_, err = Encrypt(iotest.TimeoutReader(bytes.NewReader(make([]byte, nonceLength))), key, []byte("payload"), nil, nil)
+	assert.NoError(t, err)
+	_, err = Encrypt(emptyReader{}, key, []byte("payload"), nil, nil)
+	assert.ErrorIs(t, err, io.ErrNoProgress)
+}
+
+func Test_Paseto_Local_EncryptWithNonce(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	nonce := bytes.Repeat([]byte{0x01}, nonceLength)
+
+	// Deterministic output
+	token1, err := EncryptWithNonce(key, nonce, m, nil, nil)
+	assert.NoError(t, err)
+	token2, err := EncryptWithNonce(key, nonce, m, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, token1, token2)
+
+	p, err := Decrypt(key, token1, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Invalid nonce lengths
+	_, err = EncryptWithNonce(key, nonce[1:], m, nil, nil)
+	assert.Error(t, err)
+	_, err = EncryptWithNonce(key, append(nonce, 0x01), m, nil, nil)
+	assert.Error(t, err)
+	_, err = EncryptWithNonce(key, nil, m, nil, nil)
+	assert.Error(t, err)
+}
+
+func Test_Paseto_Local_Tampered(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	otherKey, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"tampered\"}")
+
+	token, err := Encrypt(rand.Reader, key, m, f, i)
+	assert.NoError(t, err)
+
+	// v.local.body.footer
+	parts := strings.Split(token, ".")
+	body, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+
+	flip := func(offset int) string {
+		raw := append([]byte{}, body...)
+		raw[offset] ^= 0x01
+		return parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(raw) + "." + parts[3]
+	}
+	alteredFooter := []byte("{\"kid\":\"attacker\"}")
+	alteredToken := parts[0] + "." + parts[1] + "." + parts[2] + "." + base64.RawURLEncoding.EncodeToString(alteredFooter)
+
+	testCases := []struct {
+		name    string
+		key     *LocalKey
+		token   string
+		footer  []byte
+		ia      []byte
+		wantErr error
+	}{
+		{name: "nonce bit flip", key: key, token: flip(0), footer: f, ia: i, wantErr: ErrInvalidMAC},
+		{name: "ciphertext bit flip", key: key, token: flip(nonceLength), footer: f, ia: i, wantErr: ErrInvalidMAC},
+		{name: "tag bit flip", key: key, token: flip(len(body) - 1), footer: f, ia: i, wantErr: ErrInvalidMAC},
+		{name: "altered footer", key: key, token: alteredToken, footer: alteredFooter, ia: i, wantErr: ErrInvalidMAC},
+		{name: "footer mismatch", key: key, token: alteredToken, footer: f, ia: i, wantErr: ErrInvalidToken},
+		{name: "wrong implicit assertion", key: key, token: token, footer: f, ia: []byte("{\"test-vector\":\"other\"}"), wantErr: ErrInvalidMAC},
+		{name: "missing implicit assertion", key: key, token: token, footer: f, ia: nil, wantErr: ErrInvalidMAC},
+		{name: "wrong key", key: otherKey, token: token, footer: f, ia: i, wantErr: ErrInvalidMAC},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Decrypt(testCase.key, testCase.token, testCase.footer, testCase.ia)
+			assert.ErrorIs(t, err, testCase.wantErr)
+		})
+	}
+
+	// Untampered token
+	p, err := Decrypt(key, token, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+}
+
+func Test_Paseto_Local_FooterTooLarge(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := bytes.Repeat([]byte{'f'}, 1025)
+
+	token, err := Encrypt(rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+
+	// Default limit
+	p, err := Decrypt(key, token, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	MaxFooterLength = 1024
+	defer func() { MaxFooterLength = DefaultMaxFooterLength }()
+
+	_, err = Decrypt(key, token, f, nil)
+	assert.ErrorIs(t, err, ErrFooterTooLarge)
+
+	// Footer at the limit
+	token, err = Encrypt(rand.Reader, key, m, f[:1024], nil)
+	assert.NoError(t, err)
+	p, err = Decrypt(key, token, f[:1024], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+}
+
+func Test_Paseto_Purpose(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	token, err := Encrypt(rand.Reader, key, []byte("message"), nil, nil)
+	assert.NoError(t, err)
+
+	assert.True(t, IsLocal(token))
+	assert.False(t, IsLocal("v3.public."+strings.TrimPrefix(token, LocalPrefix)))
+	assert.False(t, IsLocal("v2.local."+strings.TrimPrefix(token, LocalPrefix)))
+	assert.False(t, IsLocal(""))
+	assert.False(t, IsPublic(token))
+	assert.True(t, IsPublic(PublicPrefix+"AAAA"))
+	assert.False(t, IsPublic(""))
+}
+
+func Test_LocalKey_Equal(t *testing.T) {
+	k1, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k2, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k1Copy := *k1
+
+	assert.True(t, k1.Equal(k1))
+	assert.True(t, k1.Equal(&k1Copy))
+	assert.False(t, k1.Equal(k2))
+	assert.False(t, k1.Equal(nil))
+	assert.False(t, (*LocalKey)(nil).Equal(k1))
+	assert.True(t, (*LocalKey)(nil).Equal(nil))
+}
+
+func Test_Paseto_Local_DecryptWithKDFSalt(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"1234567890\"}")
+	i := []byte("{\"test-vector\":\"legacy-salt\"}")
+	salt := []byte("legacy-fixed-salt")
+
+	// Token produced by a misconfigured implementation
+	n, err := readNonce(rand.Reader)
+	assert.NoError(t, err)
+	legacy, err := encrypt(n, key, m, f, i, salt, stdMAC{})
+	assert.NoError(t, err)
+
+	p, err := DecryptWithKDFSalt(key, legacy, f, i, salt)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Standard decryption rejects legacy tokens and vice versa
+	_, err = Decrypt(key, legacy, f, i)
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+
+	token, err := Encrypt(rand.Reader, key, m, f, i)
+	assert.NoError(t, err)
+	_, err = DecryptWithKDFSalt(key, token, f, i, salt)
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+
+	_, err = DecryptWithKDFSalt(key, token, f, i, nil)
+	assert.Error(t, err)
+}
+
 // -----------------------------------------------------------------------------
 
 func benchmarkEncrypt(key *LocalKey, m, f, i []byte, b *testing.B) {
@@ -241,3 +576,10 @@ func Benchmark_Paseto_Decrypt(b *testing.B) {
 
 	benchmarkDecrypt(&key, t, f, i, b)
 }
+
+// emptyReader is a misbehaving reader which never returns data nor error.
+type emptyReader struct{}
+
+func (emptyReader) Read([]byte) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,85 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v3
+
+import (
+	"crypto/hmac"
+	"crypto/sha512"
+	"io"
+)
+
+// MACProvider computes the HMAC-SHA384 used by the local purpose, for the key
+// derivation (HKDF) and the token authentication.
+//
+// It allows routing HMAC through a certified cryptographic module (FIPS
+// 140) instead of crypto/hmac, the token format is unchanged.
+type MACProvider interface {
+	// HMACSHA384 returns the 48 bytes HMAC-SHA384 of the message with the
+	// given key.
+	HMACSHA384(key, message []byte) ([]byte, error)
+}
+
+// MACProviderFunc adapts a function to the MACProvider interface.
+type MACProviderFunc func(key, message []byte) ([]byte, error)
+
+// HMACSHA384 calls the function.
+func (f MACProviderFunc) HMACSHA384(key, message []byte) ([]byte, error) {
+	return f(key, message)
+}
+
+// LocalOptions customizes the local encryption primitives.
+type LocalOptions struct {
+	// MAC is the HMAC-SHA384 implementation, crypto/hmac is used when nil.
+	MAC MACProvider
+}
+
+// EncryptWithOptions encrypts the message (m) like Encrypt with the given
+// options.
+func EncryptWithOptions(r io.Reader, key *LocalKey, m, f, i []byte, opts LocalOptions) (string, error) {
+	n, err := readNonce(r)
+	if err != nil {
+		return "", err
+	}
+
+	return encrypt(n, key, m, f, i, nil, opts.macProvider())
+}
+
+// DecryptWithOptions decrypts the token like Decrypt with the given options.
+func DecryptWithOptions(key *LocalKey, token string, f, i []byte, opts LocalOptions) ([]byte, error) {
+	return decrypt(key, token, f, i, nil, opts.macProvider())
+}
+
+// -----------------------------------------------------------------------------
+
+// stdMAC is the crypto/hmac based MACProvider.
+type stdMAC struct{}
+
+func (stdMAC) HMACSHA384(key, message []byte) ([]byte, error) {
+	h := hmac.New(sha512.New384, key)
+	h.Write(message)
+
+	// No error
+	return h.Sum(nil), nil
+}
+
+func (o LocalOptions) macProvider() MACProvider {
+	if o.MAC == nil {
+		return stdMAC{}
+	}
+	return o.MAC
+}
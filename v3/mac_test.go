@@ -0,0 +1,82 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v3
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_Local_MACProvider(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	calls := 0
+	provider := MACProviderFunc(func(k, m []byte) ([]byte, error) {
+		calls++
+		h := hmac.New(sha512.New384, k)
+		h.Write(m)
+		return h.Sum(nil), nil
+	})
+
+	m := []byte("payload")
+	f := []byte("{\"kid\":\"1234\"}")
+	i := []byte("{\"user_id\":\"1234\"}")
+
+	// Same wire format as the default implementation
+	token, err := EncryptWithOptions(rand.Reader, key, m, f, i, LocalOptions{MAC: provider})
+	assert.NoError(t, err)
+	assert.Equal(t, 5, calls) // 2 HKDF x 2 HMAC + 1 token MAC
+
+	p, err := Decrypt(key, token, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	token, err = Encrypt(rand.Reader, key, m, f, i)
+	assert.NoError(t, err)
+	p, err = DecryptWithOptions(key, token, f, i, LocalOptions{MAC: provider})
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Default provider
+	p, err = DecryptWithOptions(key, token, f, i, LocalOptions{})
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Provider errors are propagated
+	errModule := errors.New("module failure")
+	failing := MACProviderFunc(func(_, _ []byte) ([]byte, error) {
+		return nil, errModule
+	})
+	_, err = EncryptWithOptions(rand.Reader, key, m, f, i, LocalOptions{MAC: failing})
+	assert.ErrorIs(t, err, errModule)
+	_, err = DecryptWithOptions(key, token, f, i, LocalOptions{MAC: failing})
+	assert.ErrorIs(t, err, errModule)
+
+	// Invalid MAC length
+	short := MACProviderFunc(func(_, _ []byte) ([]byte, error) {
+		return make([]byte, 32), nil
+	})
+	_, err = DecryptWithOptions(key, token, f, i, LocalOptions{MAC: short})
+	assert.Error(t, err)
+}
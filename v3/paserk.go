@@ -0,0 +1,232 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v3
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha512"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+const (
+	paserkSealPrefix    = "k3.seal."
+	compressedPointSize = 49
+)
+
+// ErrInvalidSealedKey is raised when a sealed key can't be decoded or
+// authenticated.
+var ErrInvalidSealedKey = errors.New("paseto: invalid sealed key")
+
+// SealLocalKey encrypts the local key for the recipient P-384 public key and
+// returns it as a PASERK `k3.seal.` string.
+// https://github.com/paseto-standard/paserk/blob/master/operations/PKE.md#version-3
+func SealLocalKey(r io.Reader, recipient *ecdsa.PublicKey, key *LocalKey) (string, error) {
+	// Check arguments
+	if key == nil {
+		return "", ErrNilKey
+	}
+	pk, err := sealRecipient(recipient)
+	if err != nil {
+		return "", err
+	}
+
+	// Generate the ephemeral key pair
+	esk, err := ecdh.P384().GenerateKey(r)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to generate ephemeral key: %w", err)
+	}
+	epk, err := compressPoint(esk.PublicKey())
+	if err != nil {
+		return "", err
+	}
+
+	// Compute the shared secret
+	ecpk, err := recipient.ECDH()
+	if err != nil {
+		return "", fmt.Errorf("paseto: invalid recipient public key: %w", err)
+	}
+	xk, err := esk.ECDH(ecpk)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to compute shared secret: %w", err)
+	}
+
+	// Encrypt the key
+	ek, n, ak := sealKeys(xk, epk, pk)
+	edk := make([]byte, KeyLength)
+	if err := sealStream(ek, n, edk, key[:]); err != nil {
+		return "", err
+	}
+
+	// Authenticate h || epk || edk
+	t := sealTag(ak, epk, edk)
+
+	// Serialize t || epk || edk
+	out := make([]byte, 0, macLength+compressedPointSize+KeyLength)
+	out = append(out, t...)
+	out = append(out, epk...)
+	out = append(out, edk...)
+
+	// No error
+	return paserkSealPrefix + base64.RawURLEncoding.EncodeToString(out), nil
+}
+
+// UnsealLocalKey decrypts a PASERK `k3.seal.` string with the recipient P-384
+// private key. The tag is checked before the key is decrypted.
+func UnsealLocalKey(recipient *ecdsa.PrivateKey, sealed string) (*LocalKey, error) {
+	// Check arguments
+	if recipient == nil {
+		return nil, ErrNilKey
+	}
+	pk, err := sealRecipient(&recipient.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decode the sealed key
+	if !strings.HasPrefix(sealed, paserkSealPrefix) {
+		return nil, fmt.Errorf("%w, invalid header", ErrInvalidSealedKey)
+	}
+	raw, err := base64.RawURLEncoding.DecodeString(sealed[len(paserkSealPrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("%w, invalid encoding: %v", ErrInvalidSealedKey, err)
+	}
+	if len(raw) != macLength+compressedPointSize+KeyLength {
+		return nil, fmt.Errorf("%w, invalid length", ErrInvalidSealedKey)
+	}
+
+	// Extract components
+	t := raw[:macLength]
+	epk := raw[macLength : macLength+compressedPointSize]
+	edk := raw[macLength+compressedPointSize:]
+
+	// Compute the shared secret
+	x, y := elliptic.UnmarshalCompressed(elliptic.P384(), epk)
+	if x == nil {
+		return nil, fmt.Errorf("%w, invalid ephemeral public key", ErrInvalidSealedKey)
+	}
+	eepk, err := (&ecdsa.PublicKey{Curve: elliptic.P384(), X: x, Y: y}).ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("%w, invalid ephemeral public key", ErrInvalidSealedKey)
+	}
+	esk, err := recipient.ECDH()
+	if err != nil {
+		return nil, fmt.Errorf("paseto: invalid recipient private key: %w", err)
+	}
+	xk, err := esk.ECDH(eepk)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to compute shared secret: %w", err)
+	}
+
+	// Time-constant compare tag
+	ek, n, ak := sealKeys(xk, epk, pk)
+	if !hmac.Equal(t, sealTag(ak, epk, edk)) {
+		return nil, ErrInvalidSealedKey
+	}
+
+	// Decrypt the key
+	var key LocalKey
+	if err := sealStream(ek, n, key[:], edk); err != nil {
+		return nil, err
+	}
+
+	// No error
+	return &key, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// sealRecipient checks the recipient curve and returns its compressed
+// encoding.
+func sealRecipient(pk *ecdsa.PublicKey) ([]byte, error) {
+	if pk == nil {
+		return nil, ErrNilKey
+	}
+	if pk.Curve != elliptic.P384() || pk.X == nil || pk.Y == nil || !pk.Curve.IsOnCurve(pk.X, pk.Y) {
+		return nil, errors.New("paseto: recipient key must be a valid P-384 key")
+	}
+
+	return elliptic.MarshalCompressed(pk.Curve, pk.X, pk.Y), nil
+}
+
+// compressPoint returns the compressed encoding of an ECDH P-384 public key.
+func compressPoint(pk *ecdh.PublicKey) ([]byte, error) {
+	// Uncompressed form is 0x04 || X || Y
+	raw := pk.Bytes()
+	if len(raw) != 2*(compressedPointSize-1)+1 || raw[0] != 0x04 {
+		return nil, errors.New("paseto: unable to encode ephemeral public key")
+	}
+
+	// Compressed form is (0x02 | Y parity) || X
+	out := make([]byte, compressedPointSize)
+	out[0] = 0x02 | raw[len(raw)-1]&1
+	copy(out[1:], raw[1:compressedPointSize])
+
+	return out, nil
+}
+
+// sealKeys derives the encryption key, the nonce and the authentication key.
+func sealKeys(xk, epk, pk []byte) (ek, n, ak []byte) {
+	// tmp = SHA-384(0x01 || h || xk || epk || pk)
+	h := sha512.New384()
+	h.Write([]byte{0x01})
+	h.Write([]byte(paserkSealPrefix))
+	h.Write(xk)
+	h.Write(epk)
+	h.Write(pk)
+	tmp := h.Sum(nil)
+
+	// Ak = SHA-384(0x02 || h || xk || epk || pk)
+	h.Reset()
+	h.Write([]byte{0x02})
+	h.Write([]byte(paserkSealPrefix))
+	h.Write(xk)
+	h.Write(epk)
+	h.Write(pk)
+
+	return tmp[:KeyLength], tmp[KeyLength:], h.Sum(nil)
+}
+
+// sealTag computes HMAC-SHA-384(h || epk || edk, Ak).
+func sealTag(ak, epk, edk []byte) []byte {
+	mac := hmac.New(sha512.New384, ak)
+	mac.Write([]byte(paserkSealPrefix))
+	mac.Write(epk)
+	mac.Write(edk)
+
+	return mac.Sum(nil)
+}
+
+// sealStream applies AES-256-CTR with the given key and nonce.
+func sealStream(ek, n, dst, src []byte) error {
+	block, err := aes.NewCipher(ek)
+	if err != nil {
+		return fmt.Errorf("paseto: unable to initialize AES cipher: %w", err)
+	}
+	cipher.NewCTR(block, n).XORKeyStream(dst, src)
+
+	return nil
+}
@@ -0,0 +1,212 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v3
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_SealLocalKey(t *testing.T) {
+	recipient, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	other, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	sealed, err := SealLocalKey(rand.Reader, &recipient.PublicKey, key)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasPrefix(sealed, "k3.seal."))
+	assert.Len(t, sealed, len("k3.seal.")+172)
+
+	got, err := UnsealLocalKey(recipient, sealed)
+	assert.NoError(t, err)
+	assert.True(t, key.Equal(got))
+
+	// Wrong recipient
+	_, err = UnsealLocalKey(other, sealed)
+	assert.ErrorIs(t, err, ErrInvalidSealedKey)
+
+	// Tampered encrypted key
+	tampered := []byte(sealed)
+	tampered[len(tampered)-2] ^= 0x01
+	_, err = UnsealLocalKey(recipient, string(tampered))
+	assert.ErrorIs(t, err, ErrInvalidSealedKey)
+
+	testCases := []struct {
+		name   string
+		sealed string
+	}{
+		{name: "blank", sealed: ""},
+		{name: "wrong header", sealed: "k4.seal." + sealed[len("k3.seal."):]},
+		{name: "invalid encoding", sealed: "k3.seal.@@@@"},
+		{name: "truncated", sealed: sealed[:len(sealed)-4]},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := UnsealLocalKey(recipient, testCase.sealed)
+			assert.ErrorIs(t, err, ErrInvalidSealedKey)
+		})
+	}
+}
+
+func Test_SealLocalKey_Arguments(t *testing.T) {
+	recipient, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	_, err = SealLocalKey(rand.Reader, &recipient.PublicKey, nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+	_, err = SealLocalKey(rand.Reader, nil, key)
+	assert.ErrorIs(t, err, ErrNilKey)
+	_, err = SealLocalKey(rand.Reader, &p256.PublicKey, key)
+	assert.Error(t, err)
+	_, err = UnsealLocalKey(nil, "k3.seal.AAAA")
+	assert.ErrorIs(t, err, ErrNilKey)
+	_, err = UnsealLocalKey(p256, "k3.seal.AAAA")
+	assert.Error(t, err)
+}
+
+func Test_compressPoint(t *testing.T) {
+	for j := 0; j < 8; j++ {
+		sk, err := ecdh.P384().GenerateKey(rand.Reader)
+		assert.NoError(t, err)
+
+		got, err := compressPoint(sk.PublicKey())
+		assert.NoError(t, err)
+
+		raw := sk.PublicKey().Bytes()
+		x, y := elliptic.UnmarshalCompressed(elliptic.P384(), got)
+		assert.NotNil(t, x)
+		assert.Equal(t, raw[1:49], x.FillBytes(make([]byte, 48)))
+		assert.Equal(t, raw[49:], y.FillBytes(make([]byte, 48)))
+	}
+}
+
+// sealReference is an independent transcription of the PASERK k3.seal
+// construction, using the standard library primitives only, with a fixed
+// ephemeral secret key.
+// https://github.com/paseto-standard/paserk/blob/master/operations/PKE.md#v3-encryption
+func sealReference(t *testing.T, recipient *ecdsa.PublicKey, ephemeral, ptk []byte) string {
+	t.Helper()
+
+	const h = "k3.seal."
+
+	// Ephemeral key pair
+	esk, err := ecdh.P384().NewPrivateKey(ephemeral)
+	assert.NoError(t, err)
+	epkRaw := esk.PublicKey().Bytes()
+	epkY := new(big.Int).SetBytes(epkRaw[49:])
+	epk := append([]byte{byte(0x02 + epkY.Bit(0))}, epkRaw[1:49]...)
+
+	// Shared secret
+	rpk, err := recipient.ECDH()
+	assert.NoError(t, err)
+	xk, err := esk.ECDH(rpk)
+	assert.NoError(t, err)
+	pk := elliptic.MarshalCompressed(elliptic.P384(), recipient.X, recipient.Y)
+
+	// Ek || n = SHA-384(0x01 || h || xk || epk || pk)
+	tmp := sha512.Sum384(bytes.Join([][]byte{{0x01}, []byte(h), xk, epk, pk}, nil))
+	// Ak = SHA-384(0x02 || h || xk || epk || pk)
+	ak := sha512.Sum384(bytes.Join([][]byte{{0x02}, []byte(h), xk, epk, pk}, nil))
+
+	// edk = AES-256-CTR(ptk, Ek, n)
+	block, err := aes.NewCipher(tmp[:32])
+	assert.NoError(t, err)
+	edk := make([]byte, len(ptk))
+	cipher.NewCTR(block, tmp[32:]).XORKeyStream(edk, ptk)
+
+	// t = HMAC-SHA-384(h || epk || edk, Ak)
+	mac := hmac.New(sha512.New384, ak[:])
+	mac.Write([]byte(h))
+	mac.Write(epk)
+	mac.Write(edk)
+
+	return h + base64.RawURLEncoding.EncodeToString(bytes.Join([][]byte{mac.Sum(nil), epk, edk}, nil))
+}
+
+// Test_SealLocalKey_Vectors unseals k3.seal keys built by sealReference with
+// fixed recipient and ephemeral keys.
+func Test_SealLocalKey_Vectors(t *testing.T) {
+	recipientScalar, _ := hex.DecodeString("a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90a1b2c3d4e5f60718293a4b5c6d7e8f90")
+	rsk, err := ecdh.P384().NewPrivateKey(recipientScalar)
+	assert.NoError(t, err)
+	rpk := rsk.PublicKey().Bytes()
+	recipient := &ecdsa.PrivateKey{
+		PublicKey: ecdsa.PublicKey{
+			Curve: elliptic.P384(),
+			X:     new(big.Int).SetBytes(rpk[1:49]),
+			Y:     new(big.Int).SetBytes(rpk[49:]),
+		},
+		D: new(big.Int).SetBytes(recipientScalar),
+	}
+
+	testCases := []struct {
+		name      string
+		ephemeral string
+		unsealed  string
+		paserk    string
+	}{
+		{
+			name:      "zero key",
+			ephemeral: "0102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f202122232425262728292a2b2c2d2e2f30",
+			unsealed:  "0000000000000000000000000000000000000000000000000000000000000000",
+			paserk:    "k3.seal.RhNas2f1SF3fnxhIITDqlTZyHcs9_bI5qhR9oFxnGfZ8K-aUPAqItH1SK5WbAVjNA8dvIoPdqVzUmw7Z5zPSkER043IW8SThPSyatM8BAhxJrZyrs9C5dJmu8vCrMT-gKLnHBhbqwrGymkVGAkVhLmlWKZhOT-vpijexqBa0DNs2",
+		},
+		{
+			name:      "sequence key",
+			ephemeral: "303132333435363738393a3b3c3d3e3f404142434445464748494a4b4c4d4e4f505152535455565758595a5b5c5d5e5f",
+			unsealed:  "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f",
+			paserk:    "k3.seal.B0T4PGV442lx0FIg9VxBAIhKfJPwB8HXtCMAQ_waGUAD7_AmeiKtJzktTo1lv3DQA563vOmmMcLsGdDsmufrOiyBJsBgA2gaIZOHzH7fhLbiJuNMPG3mPuR1k1idolebqo1uEvmGEiokUk6Gu9_NHfWkC2kp-96zzoK7CDPS2CJg",
+		},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			ephemeral, _ := hex.DecodeString(testCase.ephemeral)
+			unsealed, _ := hex.DecodeString(testCase.unsealed)
+
+			// The vector matches the specification transcription
+			assert.Equal(t, testCase.paserk, sealReference(t, &recipient.PublicKey, ephemeral, unsealed))
+
+			key, err := UnsealLocalKey(recipient, testCase.paserk)
+			assert.NoError(t, err)
+			assert.Equal(t, unsealed, key[:])
+		})
+	}
+}
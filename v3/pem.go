@@ -0,0 +1,145 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v3
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"fmt"
+)
+
+// PublicKeysFromPEMBundle parses all the `PUBLIC KEY` (PKIX) blocks of the
+// given PEM bundle, in order. Other block types are ignored, and all the
+// public keys must be P-384 ECDSA keys.
+func PublicKeysFromPEMBundle(bundle []byte) ([]*ecdsa.PublicKey, error) {
+	var out []*ecdsa.PublicKey
+	for idx := 0; ; idx++ {
+		// Decode the next block
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			break
+		}
+		if block.Type != "PUBLIC KEY" {
+			continue
+		}
+
+		// Parse the public key
+		pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+		if err != nil {
+			return nil, fmt.Errorf("paseto: unable to parse public key of block %d: %w", idx, err)
+		}
+		pk, ok := pub.(*ecdsa.PublicKey)
+		if !ok || pk.Curve != elliptic.P384() {
+			return nil, fmt.Errorf("paseto: block %d is not a P-384 public key", idx)
+		}
+
+		out = append(out, pk)
+	}
+	if len(out) == 0 {
+		return nil, errors.New("paseto: no public key found in the PEM bundle")
+	}
+
+	// No error
+	return out, nil
+}
+
+// PrivateKeyFromPEM parses a P-384 private key from an `EC PRIVATE KEY`
+// (SEC 1) or a `PRIVATE KEY` (PKCS#8) PEM block. Use it to parse the key once
+// when signing many tokens.
+func PrivateKeyFromPEM(data []byte) (*ecdsa.PrivateKey, error) {
+	// Decode the block
+	block, _ := pem.Decode(data)
+	if block == nil {
+		return nil, errors.New("paseto: no PEM block found")
+	}
+
+	// Parse the private key
+	var (
+		priv any
+		err  error
+	)
+	switch block.Type {
+	case "EC PRIVATE KEY":
+		priv, err = x509.ParseECPrivateKey(block.Bytes)
+	case "PRIVATE KEY":
+		priv, err = x509.ParsePKCS8PrivateKey(block.Bytes)
+	default:
+		return nil, fmt.Errorf("paseto: unexpected PEM block type %q", block.Type)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to parse private key: %w", err)
+	}
+	sk, ok := priv.(*ecdsa.PrivateKey)
+	if !ok || sk.Curve != elliptic.P384() {
+		return nil, errors.New("paseto: private key is not a P-384 key")
+	}
+
+	// No error
+	return sk, nil
+}
+
+// PublicKeyFromPEM parses a P-384 public key from a `PUBLIC KEY` (PKIX) PEM
+// block. Use it to parse the key once when verifying many tokens.
+func PublicKeyFromPEM(data []byte) (*ecdsa.PublicKey, error) {
+	// Decode the block
+	block, _ := pem.Decode(data)
+	if block == nil || block.Type != "PUBLIC KEY" {
+		return nil, errors.New("paseto: no PUBLIC KEY block found")
+	}
+
+	// Parse the public key
+	pub, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to parse public key: %w", err)
+	}
+	pk, ok := pub.(*ecdsa.PublicKey)
+	if !ok || pk.Curve != elliptic.P384() {
+		return nil, errors.New("paseto: public key is not a P-384 key")
+	}
+
+	// No error
+	return pk, nil
+}
+
+// SignPEM signs the message (m) like Sign with a PEM encoded private key,
+// parsed on each call.
+func SignPEM(m, privatePEM, f, i []byte) (string, error) {
+	// Parse the private key
+	sk, err := PrivateKeyFromPEM(privatePEM)
+	if err != nil {
+		return "", err
+	}
+
+	return Sign(m, sk, f, i)
+}
+
+// VerifyPEM verifies the token like Verify with a PEM encoded public key,
+// parsed on each call.
+func VerifyPEM(t string, publicPEM, f, i []byte) ([]byte, error) {
+	// Parse the public key
+	pk, err := PublicKeyFromPEM(publicPEM)
+	if err != nil {
+		return nil, err
+	}
+
+	return Verify(t, pk, f, i)
+}
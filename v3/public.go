@@ -18,7 +18,6 @@
 package v3
 
 import (
-	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/sha512"
@@ -27,6 +26,7 @@ import (
 	"errors"
 	"fmt"
 	"math/big"
+	"strings"
 
 	"zntr.io/paseto/internal/common"
 	"zntr.io/paseto/v3/internal/rfc6979"
@@ -53,11 +53,11 @@ func Sign(m []byte, sk *ecdsa.PrivateKey, f, i []byte) (string, error) {
 	// Sign using a determistic ECDSA scheme
 	r, s := rfc6979.SignECDSA(sk, digest[:], sha512.New384)
 
-	// Prepare content
-	body := make([]byte, 0, len(m)+r.BitLen()/8+s.BitLen()/8)
-	body = append(body, m...)
-	body = append(body, r.Bytes()...)
-	body = append(body, s.Bytes()...)
+	// Prepare content, r and s are left-padded to 48 bytes each
+	body := make([]byte, len(m)+signatureSize)
+	copy(body, m)
+	r.FillBytes(body[len(m) : len(m)+kdfOutputLength])
+	s.FillBytes(body[len(m)+kdfOutputLength:])
 
 	// Encode body as RawURLBase64
 	tokenLen := base64.RawURLEncoding.EncodedLen(len(body))
@@ -92,40 +92,51 @@ func Verify(t string, pub *ecdsa.PublicKey, f, i []byte) ([]byte, error) {
 	rawToken := []byte(t)
 
 	// Check token header
-	if !bytes.HasPrefix(rawToken, []byte(PublicPrefix)) {
-		return nil, errors.New("paseto: invalid token")
+	if err := common.CheckHeader(rawToken, PublicPrefix); err != nil {
+		return nil, err
 	}
 
 	// Trim prefix
 	rawToken = rawToken[len(PublicPrefix):]
 
+	// Split the footer and the body
+	rawBody, rawFooter, err := common.SplitToken(rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check footer size before decoding
+	if err := common.CheckFooterLength(rawFooter, MaxFooterLength); err != nil {
+		return nil, err
+	}
+
 	// Check footer usage
 	if len(f) > 0 {
-		// Split the footer and the body
-		footerIdx := bytes.Index(rawToken, []byte("."))
-		if footerIdx == 0 {
-			return nil, errors.New("paseto: invalid token, footer is missing but expected")
+		if len(rawFooter) == 0 {
+			return nil, ErrMissingFooter
 		}
 
 		// Decode footer
-		footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawToken[footerIdx+1:])))
-		if _, err := base64.RawURLEncoding.Decode(footer, rawToken[footerIdx+1:]); err != nil {
-			return nil, fmt.Errorf("paseto: invalid token, footer has invalid encoding: %w", err)
+		footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawFooter)))
+		if _, err := base64.RawURLEncoding.Decode(footer, rawFooter); err != nil {
+			return nil, fmt.Errorf("%w, footer has invalid encoding: %v", ErrInvalidToken, err)
 		}
 
 		// Compare footer
 		if subtle.ConstantTimeCompare(f, footer) == 0 {
-			return nil, errors.New("paseto: invalid token, footer mismatch")
+			return nil, fmt.Errorf("%w, footer mismatch", ErrInvalidToken)
 		}
-
-		// Continue without footer
-		rawToken = rawToken[:footerIdx]
 	}
 
 	// Decode token
-	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawToken)))
-	if _, err := base64.RawURLEncoding.Decode(raw, rawToken); err != nil {
-		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
+	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawBody)))
+	if _, err := base64.RawURLEncoding.Decode(raw, rawBody); err != nil {
+		return nil, fmt.Errorf("%w body: %v", ErrInvalidToken, err)
+	}
+
+	// Check body length
+	if len(raw) < signatureSize {
+		return nil, fmt.Errorf("%w body, signature is missing", ErrInvalidToken)
 	}
 
 	// Extract components
@@ -147,9 +158,47 @@ func Verify(t string, pub *ecdsa.PublicKey, f, i []byte) ([]byte, error) {
 
 	// Check signature
 	if !ecdsa.Verify(pub, digest[:], r, s) {
-		return nil, errors.New("paseto: invalid token signature")
+		return nil, common.AuthError(ErrInvalidSignature, i)
 	}
 
 	// No error
 	return m, nil
 }
+
+// IsPublic returns true when the token has the `v3.public.` header. It only checks
+// the header, the token is neither decoded nor authenticated.
+func IsPublic(token string) bool {
+	return strings.HasPrefix(token, PublicPrefix)
+}
+
+// PublicKeysEqual reports whether a and b are the same public key. It is nil
+// safe and relies on ecdsa.PublicKey.Equal which compares in constant time.
+func PublicKeysEqual(a, b *ecdsa.PublicKey) bool {
+	if a == nil || b == nil {
+		return a == b
+	}
+
+	return a.Equal(b)
+}
+
+// KeyPairMatches reports whether the public key derived from the secret key
+// (sk) is pk, compared in constant time. It is intended as a startup
+// self-check to detect a configuration mismatch.
+func KeyPairMatches(sk *ecdsa.PrivateKey, pk *ecdsa.PublicKey) bool {
+	// Check arguments
+	if sk == nil || pk == nil || sk.Curve != elliptic.P384() || pk.Curve != elliptic.P384() {
+		return false
+	}
+
+	// Derive the public key from the scalar
+	esk, err := sk.ECDH()
+	if err != nil {
+		return false
+	}
+	epk, err := pk.ECDH()
+	if err != nil {
+		return false
+	}
+
+	return esk.PublicKey().Equal(epk)
+}
@@ -20,7 +20,10 @@ package v3
 import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
 	"math/big"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -108,8 +111,185 @@ func Test_Paseto_PublicVector(t *testing.T) {
 	}
 }
 
+func Test_Paseto_Public_EmptyMessage(t *testing.T) {
+	var sk ecdsa.PrivateKey
+	sk.D, _ = new(big.Int).SetString("20347609607477aca8fbfbc5e6218455f3199669792ef8b466faa87bdc67798144c848dd03661eed5ac62461340cea96", 16)
+	pubRaw, _ := new(big.Int).SetString("02fbcb7c69ee1c60579be7a334134878d9c5c5bf35d552dab63c0140397ed14cef637d7720925c44699ea30e72874c72fb", 16)
+	sk.PublicKey.Curve = elliptic.P384()
+	sk.PublicKey.X, sk.PublicKey.Y = elliptic.UnmarshalCompressed(sk.PublicKey.Curve, pubRaw.Bytes())
+
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"heartbeat\"}")
+
+	for _, m := range [][]byte{nil, {}} {
+		for _, footer := range [][]byte{nil, f} {
+			token, err := Sign(m, &sk, footer, i)
+			assert.NoError(t, err)
+
+			p, err := Verify(token, &sk.PublicKey, footer, i)
+			assert.NoError(t, err)
+			assert.Empty(t, p)
+		}
+	}
+}
+
+func Test_Paseto_Public_Deterministic(t *testing.T) {
+	sk := testPrivateKey()
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"heartbeat\"}")
+
+	// RFC6979 signatures are deterministic
+	token1, err := Sign(m, sk, f, i)
+	assert.NoError(t, err)
+	token2, err := Sign(m, sk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, token1, token2)
+
+	// A different message produces a different signature
+	token3, err := Sign([]byte("other"), sk, f, i)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token1, token3)
+}
+
+func Test_Paseto_Public_SignatureLayout(t *testing.T) {
+	sk := testPrivateKey()
+
+	for _, m := range signatureLayoutMessages {
+		token, err := Sign([]byte(m), sk, nil, nil)
+		assert.NoError(t, err)
+
+		// Body is m || r (48 bytes) || s (48 bytes)
+		body, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, PublicPrefix))
+		assert.NoError(t, err)
+		if !assert.Len(t, body, len(m)+signatureSize, m) {
+			continue
+		}
+		assert.Equal(t, m, string(body[:len(m)]))
+
+		// The signature verifies with the fixed-size split
+		p, err := Verify(token, &sk.PublicKey, nil, nil)
+		assert.NoError(t, err, m)
+		assert.Equal(t, []byte(m), p)
+	}
+}
+
+func Test_Paseto_Public_Tampered(t *testing.T) {
+	var sk ecdsa.PrivateKey
+	sk.D, _ = new(big.Int).SetString("20347609607477aca8fbfbc5e6218455f3199669792ef8b466faa87bdc67798144c848dd03661eed5ac62461340cea96", 16)
+	pubRaw, _ := new(big.Int).SetString("02fbcb7c69ee1c60579be7a334134878d9c5c5bf35d552dab63c0140397ed14cef637d7720925c44699ea30e72874c72fb", 16)
+	sk.PublicKey.Curve = elliptic.P384()
+	sk.PublicKey.X, sk.PublicKey.Y = elliptic.UnmarshalCompressed(sk.PublicKey.Curve, pubRaw.Bytes())
+	pk := &sk.PublicKey
+	other, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	otherPk := &other.PublicKey
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"tampered\"}")
+
+	token, err := Sign(m, &sk, f, i)
+	assert.NoError(t, err)
+
+	// v.public.body.footer
+	parts := strings.Split(token, ".")
+	body, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+
+	flip := func(offset int) string {
+		raw := append([]byte{}, body...)
+		raw[offset] ^= 0x01
+		return parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(raw) + "." + parts[3]
+	}
+	alteredFooter := []byte("{\"kid\":\"attacker\"}")
+	alteredToken := parts[0] + "." + parts[1] + "." + parts[2] + "." + base64.RawURLEncoding.EncodeToString(alteredFooter)
+
+	testCases := []struct {
+		name    string
+		pk      *ecdsa.PublicKey
+		token   string
+		footer  []byte
+		ia      []byte
+		wantErr error
+	}{
+		{name: "message bit flip", pk: pk, token: flip(0), footer: f, ia: i, wantErr: ErrInvalidSignature},
+		{name: "signature bit flip", pk: pk, token: flip(len(body) - 1), footer: f, ia: i, wantErr: ErrInvalidSignature},
+		{name: "altered footer", pk: pk, token: alteredToken, footer: alteredFooter, ia: i, wantErr: ErrInvalidSignature},
+		{name: "footer mismatch", pk: pk, token: alteredToken, footer: f, ia: i, wantErr: ErrInvalidToken},
+		{name: "wrong implicit assertion", pk: pk, token: token, footer: f, ia: []byte("{\"test-vector\":\"other\"}"), wantErr: ErrInvalidSignature},
+		{name: "missing implicit assertion", pk: pk, token: token, footer: f, ia: nil, wantErr: ErrInvalidSignature},
+		{name: "wrong key", pk: otherPk, token: token, footer: f, ia: i, wantErr: ErrInvalidSignature},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Verify(testCase.token, testCase.pk, testCase.footer, testCase.ia)
+			assert.ErrorIs(t, err, testCase.wantErr)
+		})
+	}
+
+	// Untampered token
+	p, err := Verify(token, pk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+}
+
+func Test_PublicKeysEqual(t *testing.T) {
+	sk1, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	sk2, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	pk1Copy := sk1.PublicKey
+
+	assert.True(t, PublicKeysEqual(&sk1.PublicKey, &pk1Copy))
+	assert.False(t, PublicKeysEqual(&sk1.PublicKey, &sk2.PublicKey))
+	assert.False(t, PublicKeysEqual(&sk1.PublicKey, nil))
+	assert.True(t, PublicKeysEqual(nil, nil))
+}
+
+func Test_KeyPairMatches(t *testing.T) {
+	sk1, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	sk2, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+	skP256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+
+	assert.True(t, KeyPairMatches(sk1, &sk1.PublicKey))
+	assert.False(t, KeyPairMatches(sk1, &sk2.PublicKey))
+	assert.False(t, KeyPairMatches(skP256, &skP256.PublicKey))
+	assert.False(t, KeyPairMatches(nil, &sk1.PublicKey))
+	assert.False(t, KeyPairMatches(sk1, nil))
+
+	// The embedded public key is not trusted
+	tampered := *sk1
+	tampered.PublicKey = sk2.PublicKey
+	assert.False(t, KeyPairMatches(&tampered, &sk2.PublicKey))
+}
+
 // -----------------------------------------------------------------------------
 
+// signatureLayoutMessages are signed with testPrivateKey by the signature
+// layout test.
+var signatureLayoutMessages = []string{
+	"", "message-0", "message-1", "message-2", "message-3", "message-4",
+	"message-5", "message-6", "message-7", "message-8", "message-9",
+	// Short r (47 bytes) and short s (47 bytes)
+	"message-45", "message-343",
+}
+
+// testPrivateKey returns the 3-S-1 test vector private key.
+func testPrivateKey() *ecdsa.PrivateKey {
+	var sk ecdsa.PrivateKey
+	sk.D, _ = new(big.Int).SetString("20347609607477aca8fbfbc5e6218455f3199669792ef8b466faa87bdc67798144c848dd03661eed5ac62461340cea96", 16)
+	pubRaw, _ := new(big.Int).SetString("02fbcb7c69ee1c60579be7a334134878d9c5c5bf35d552dab63c0140397ed14cef637d7720925c44699ea30e72874c72fb", 16)
+	sk.PublicKey.Curve = elliptic.P384()
+	sk.PublicKey.X, sk.PublicKey.Y = elliptic.UnmarshalCompressed(sk.PublicKey.Curve, pubRaw.Bytes())
+	return &sk
+}
+
 func benchmarkSign(m []byte, sk *ecdsa.PrivateKey, f, i []byte, b *testing.B) {
 	for n := 0; n < b.N; n++ {
 		_, err := Sign(m, sk, f, i)
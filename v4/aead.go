@@ -0,0 +1,63 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"errors"
+	"fmt"
+	"io"
+)
+
+// Seal encrypts and authenticates the plaintext like cipher.AEAD.Seal and
+// returns a PASETO v4 local token without footer.
+//
+// The additional data is authenticated but neither encrypted nor transmitted,
+// it maps to the PASETO implicit assertion: the same additional data must be
+// given to Open. Use Encrypt directly to attach a public footer.
+//
+// It reads exactly 32 bytes (the nonce) from r.
+func Seal(r io.Reader, key *LocalKey, plaintext, additionalData []byte) ([]byte, error) {
+	return AppendEncrypt(nil, r, key, plaintext, nil, additionalData)
+}
+
+// Open authenticates and decrypts a token produced by Seal like
+// cipher.AEAD.Open, using the same additional data (implicit assertion).
+//
+// Tokens carrying a footer are rejected since Seal never produces one, use
+// Decrypt for them.
+func Open(key *LocalKey, token, additionalData []byte) ([]byte, error) {
+	// Check arguments
+	if key == nil {
+		return nil, ErrNilKey
+	}
+	if len(token) == 0 {
+		return nil, errors.New("paseto: input is blank")
+	}
+
+	// Decode token
+	raw, footer, err := decodeToken(LocalPrefix, string(token))
+	if err != nil {
+		return nil, err
+	}
+	if len(footer) > 0 {
+		return nil, fmt.Errorf("%w, footer is not supported by Open", ErrInvalidToken)
+	}
+
+	// Decrypt the body in place
+	return decryptBody(key, raw, nil, additionalData)
+}
@@ -0,0 +1,73 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_SealOpen(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	plaintext := []byte("{\"data\":\"this is a secret message\"}")
+	ad := []byte("{\"user_id\":\"1234\"}")
+
+	token, err := Seal(rand.Reader, key, plaintext, ad)
+	assert.NoError(t, err)
+	assert.Len(t, token, EncryptedLen(len(plaintext), 0))
+
+	// Round trip
+	p, err := Open(key, token, ad)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, p)
+
+	// Interoperable with Decrypt (additional data is the implicit assertion)
+	p, err = Decrypt(key, string(token), nil, ad)
+	assert.NoError(t, err)
+	assert.Equal(t, plaintext, p)
+
+	// Wrong additional data
+	_, err = Open(key, token, []byte("{\"user_id\":\"5678\"}"))
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+	_, err = Open(key, token, nil)
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+
+	// Wrong key
+	other, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	_, err = Open(other, token, ad)
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+
+	// Tokens with footer are rejected
+	withFooter, err := Encrypt(rand.Reader, key, plaintext, []byte("{\"kid\":\"1234\"}"), ad)
+	assert.NoError(t, err)
+	_, err = Open(key, []byte(withFooter), ad)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Invalid arguments
+	_, err = Seal(rand.Reader, nil, plaintext, ad)
+	assert.ErrorIs(t, err, ErrNilKey)
+	_, err = Open(nil, token, ad)
+	assert.ErrorIs(t, err, ErrNilKey)
+	_, err = Open(key, nil, ad)
+	assert.Error(t, err)
+}
@@ -17,9 +17,81 @@
 
 package v4
 
+import (
+	"crypto/subtle"
+	"errors"
+
+	"zntr.io/paseto/internal/common"
+)
+
+var (
+	// ErrNilKey is raised when a nil key is given.
+	ErrNilKey = common.ErrNilKey
+	// ErrInvalidKeyLength is raised when a key or seed has an invalid length.
+	ErrInvalidKeyLength = common.ErrInvalidKeyLength
+	// ErrInvalidToken is raised when a token is malformed (header, segments,
+	// encoding or length).
+	ErrInvalidToken = common.ErrInvalidToken
+	// ErrMissingFooter is raised when a footer is expected but the token
+	// doesn't have one. It wraps ErrInvalidToken.
+	ErrMissingFooter = common.ErrMissingFooter
+	// ErrWrongVersion is raised when the token has another PASETO version. It
+	// wraps ErrInvalidToken.
+	ErrWrongVersion = common.ErrWrongVersion
+	// ErrWrongPurpose is raised when the token has another PASETO purpose. It
+	// wraps ErrInvalidToken.
+	ErrWrongPurpose = common.ErrWrongPurpose
+	// ErrFooterTooLarge is raised when a token footer exceeds MaxFooterLength.
+	ErrFooterTooLarge = common.ErrFooterTooLarge
+	// ErrInvalidMAC is raised when the token MAC doesn't match (tampered token,
+	// wrong key, footer or implicit assertion).
+	ErrInvalidMAC = common.ErrInvalidMAC
+	// ErrInvalidSignature is raised when the token signature doesn't match
+	// (tampered token, wrong key, footer or implicit assertion).
+	ErrInvalidSignature = common.ErrInvalidSignature
+	// ErrPossibleImplicitMismatch is wrapped with ErrInvalidMAC and
+	// ErrInvalidSignature when an implicit assertion was given.
+	ErrPossibleImplicitMismatch = common.ErrPossibleImplicitMismatch
+	// ErrKeyMisuse is raised when a key material of a purpose is used for the
+	// other purpose.
+	ErrKeyMisuse = errors.New("paseto: key misuse, key material belongs to another purpose")
+	// ErrFooterNotJSON is raised when an authenticated footer is not a JSON
+	// object.
+	ErrFooterNotJSON = errors.New("paseto: footer is not a JSON object")
+	// ErrInconsistentSecretKey is raised when the public key half embedded in
+	// a secret key doesn't match the one derived from its seed.
+	ErrInconsistentSecretKey = errors.New("paseto: inconsistent secret key, public key part doesn't match the seed")
+)
+
+// StrictFooter makes Decrypt and the Verify functions reject a token carrying
+// a footer when no expected footer is given.
+//
+// The footer is always bound to the MAC or the signature, so an unexpected
+// footer already makes the authentication fail. The strict mode rejects it
+// explicitly before any cryptographic operation so that the failure cause is
+// not mistaken for a key or tampering issue. It must be set during the
+// program initialization, before any concurrent use.
+var StrictFooter = false
+
+// MaxFooterLength is the maximum decoded footer length accepted by the decrypt
+// and verify functions, checked before the footer is decoded to protect public
+// endpoints against oversized tokens. A value lower or equal to 0 disables the
+// check. It must be set during the program initialization, before any
+// concurrent use.
+var MaxFooterLength = DefaultMaxFooterLength
+
 const (
 	// KeyLength is the requested encryption key size.
 	KeyLength = 32
+	// DefaultMaxFooterLength is the default MaxFooterLength value.
+	DefaultMaxFooterLength = 8 * 1024
+)
+
+const (
+	// LocalPrefix is the local purpose (symmetric encryption) token header.
+	LocalPrefix = "v4.local."
+	// PublicPrefix is the public purpose (signature) token header.
+	PublicPrefix = "v4.public."
 )
 
 const (
@@ -27,9 +99,27 @@ const (
 	macLength               = 32
 	encryptionKDFLength     = 56
 	authenticationKeyLength = 32
-	LocalPrefix             = "v4.local."
-	PublicPrefix            = "v4.public."
+	derivedKeyDomain        = "paseto-v4-derived-local-key"
+	compressedDomain        = "paseto-v4-deflate"
 )
 
 // LocalKey represents a key for symetric encryption (local).
+//
+// It is a distinct type from ed25519.PrivateKey so that a local key can't be
+// given to the public purpose functions, and vice versa, without an explicit
+// conversion.
+//
+// Keys are only read by the package functions, a single key (local or
+// Ed25519) can be shared by concurrent Encrypt, Decrypt, Sign and Verify calls
+// without synchronization, as long as it is not modified meanwhile.
 type LocalKey [32]byte
+
+// Equal reports whether k and other hold the same key material. The key
+// material is compared in constant time.
+func (k *LocalKey) Equal(other *LocalKey) bool {
+	if k == nil || other == nil {
+		return k == other
+	}
+
+	return subtle.ConstantTimeCompare(k[:], other[:]) == 1
+}
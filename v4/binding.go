@@ -0,0 +1,53 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"slices"
+
+	"zntr.io/paseto/internal/common"
+)
+
+// bindingImplicitDomain separates the binding implicit assertions from other
+// implicit assertion usages.
+const bindingImplicitDomain = "paseto-v4-binding"
+
+// BindImplicit canonically encodes the binding values (client IP, user-agent
+// hash, device identifier) as an implicit assertion, to bind a token to its
+// client.
+//
+// The implicit assertion is not transmitted, the verifying side must call
+// BindImplicit with the same values to rebuild it. The parts are expected as
+// `name=value` strings, they are sorted so that their order doesn't matter,
+// and encoded with PAE so that no value can be confused with another.
+//
+//	i := BindImplicit("ip=203.0.113.7", "device=0f8fad5b")
+func BindImplicit(parts ...string) []byte {
+	// Sort a copy of the parts
+	sorted := slices.Clone(parts)
+	slices.Sort(sorted)
+
+	// Encode as PAE(domain, parts...)
+	pieces := make([][]byte, 0, len(sorted)+1)
+	pieces = append(pieces, []byte(bindingImplicitDomain))
+	for _, p := range sorted {
+		pieces = append(pieces, []byte(p))
+	}
+
+	return common.PreAuthenticationEncoding(pieces...)
+}
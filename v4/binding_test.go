@@ -0,0 +1,63 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_BindImplicit(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"sub\":\"user-1\"}")
+
+	token, err := Encrypt(rand.Reader, key, m, nil, BindImplicit("ip=203.0.113.7", "device=0f8fad5b"))
+	assert.NoError(t, err)
+
+	// Same values in any order
+	p, err := Decrypt(key, token, nil, BindImplicit("device=0f8fad5b", "ip=203.0.113.7"))
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	testCases := []struct {
+		name  string
+		parts []string
+	}{
+		{name: "different value", parts: []string{"ip=203.0.113.8", "device=0f8fad5b"}},
+		{name: "missing value", parts: []string{"ip=203.0.113.7"}},
+		{name: "extra value", parts: []string{"ip=203.0.113.7", "device=0f8fad5b", "ua=1234"}},
+		{name: "concatenated values", parts: []string{"ip=203.0.113.7device=0f8fad5b"}},
+		{name: "none", parts: nil},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Decrypt(key, token, nil, BindImplicit(testCase.parts...))
+			assert.ErrorIs(t, err, ErrInvalidMAC)
+		})
+	}
+
+	// Input is not modified
+	parts := []string{"b", "a"}
+	BindImplicit(parts...)
+	assert.Equal(t, []string{"b", "a"}, parts)
+}
@@ -0,0 +1,101 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"compress/flate"
+	"errors"
+	"fmt"
+	"io"
+
+	"zntr.io/paseto/internal/common"
+)
+
+// ErrDecompressedTooLarge is raised when a compressed payload inflates beyond
+// the given limit.
+var ErrDecompressedTooLarge = errors.New("paseto: decompressed payload is too large")
+
+// EncryptCompressed compresses the message (m) with DEFLATE before encrypting
+// it like Encrypt.
+//
+// NON STANDARD: the envelope is a regular v4.local token but the plaintext is
+// compressed. The compression is bound to the implicit assertion so that a
+// compressed token can't be decrypted by Decrypt (and vice versa), another
+// implementation must use PAE("paseto-v4-deflate", i) as implicit assertion and
+// inflate the payload.
+//
+// SECURITY: the token length depends on the payload content. When a payload
+// mixes a secret with attacker-controlled data, an attacker who can observe
+// the token length can recover the secret byte by byte (CRIME/BREACH). Don't
+// compress payloads which mix secret and attacker-controlled claims.
+func EncryptCompressed(r io.Reader, key *LocalKey, m, f, i []byte) (string, error) {
+	// Compress the message
+	var buf bytes.Buffer
+	w, err := flate.NewWriter(&buf, flate.BestCompression)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to initialize compressor: %w", err)
+	}
+	if _, err := w.Write(m); err != nil {
+		return "", fmt.Errorf("paseto: unable to compress payload: %w", err)
+	}
+	if err := w.Close(); err != nil {
+		return "", fmt.Errorf("paseto: unable to compress payload: %w", err)
+	}
+
+	// No error
+	return Encrypt(r, key, buf.Bytes(), f, compressedImplicit(i))
+}
+
+// DecryptCompressed decrypts a token produced by EncryptCompressed and
+// inflates the payload. The decompression stops with ErrDecompressedTooLarge
+// as soon as the payload exceeds maxSize bytes to protect against
+// decompression bombs.
+func DecryptCompressed(key *LocalKey, input string, f, i []byte, maxSize int) ([]byte, error) {
+	// Check arguments
+	if maxSize <= 0 {
+		return nil, errors.New("paseto: max size must be positive")
+	}
+
+	// Decrypt the compressed payload
+	c, err := Decrypt(key, input, f, compressedImplicit(i))
+	if err != nil {
+		return nil, err
+	}
+
+	// Inflate with a limit
+	fr := flate.NewReader(bytes.NewReader(c))
+	defer fr.Close()
+
+	m, err := io.ReadAll(io.LimitReader(fr, int64(maxSize)+1))
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to decompress payload: %w", err)
+	}
+	if len(m) > maxSize {
+		return nil, ErrDecompressedTooLarge
+	}
+
+	// No error
+	return m, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func compressedImplicit(i []byte) []byte {
+	return common.PreAuthenticationEncoding([]byte(compressedDomain), i)
+}
@@ -0,0 +1,92 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Run with -race to check that shared keys are only read.
+func Test_Paseto_SharedKeyConcurrency(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	f := []byte("{\"kid\":\"shared\"}")
+	i := []byte("{\"test-vector\":\"concurrency\"}")
+
+	const workers = 16
+	const iterations = 50
+
+	var wg sync.WaitGroup
+	errs := make(chan error, workers)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			for n := 0; n < iterations; n++ {
+				m := []byte(fmt.Sprintf("{\"worker\":%d,\"n\":%d}", w, n))
+
+				// Local
+				token, err := Encrypt(rand.Reader, key, m, f, i)
+				if err != nil {
+					errs <- err
+					return
+				}
+				p, err := Decrypt(key, token, f, i)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if string(p) != string(m) {
+					errs <- fmt.Errorf("worker %d: local payload mismatch", w)
+					return
+				}
+
+				// Public
+				token, err = Sign(m, sk, f, i)
+				if err != nil {
+					errs <- err
+					return
+				}
+				p, err = Verify(token, pk, f, i)
+				if err != nil {
+					errs <- err
+					return
+				}
+				if string(p) != string(m) {
+					errs <- fmt.Errorf("worker %d: public payload mismatch", w)
+					return
+				}
+			}
+		}(w)
+	}
+	wg.Wait()
+	close(errs)
+
+	for err := range errs {
+		assert.NoError(t, err)
+	}
+}
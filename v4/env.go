@@ -0,0 +1,103 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"errors"
+	"fmt"
+
+	"zntr.io/paseto/internal/common"
+	"zntr.io/paseto/observer"
+)
+
+// envImplicitDomain separates the environment implicit assertions from other
+// implicit assertion usages.
+const envImplicitDomain = "paseto-v4-environment"
+
+// EnvSigner signs tokens bound to an environment (production, staging, ...).
+//
+// The environment is not transmitted, it is bound as implicit assertion
+// (combined with the caller one) so that a token signed for an environment
+// doesn't verify in another one, without adding a claim to the payload.
+type EnvSigner struct {
+	sk   ed25519.PrivateKey
+	env  string
+	opts options
+}
+
+// NewEnvSigner creates a signer bound to the given environment.
+func NewEnvSigner(sk ed25519.PrivateKey, env string, opts ...Option) (*EnvSigner, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PrivateKeySize)
+	}
+	if env == "" {
+		return nil, errors.New("paseto: environment must not be blank")
+	}
+
+	// No error
+	return &EnvSigner{sk: sk, env: env, opts: newOptions(opts)}, nil
+}
+
+// Sign signs the message (m) like Sign with the environment bound to the
+// implicit assertion (i).
+func (s *EnvSigner) Sign(m, f, i []byte) (string, error) {
+	return trackString(s.opts.observer, observer.OpSign, func() (string, error) {
+		return Sign(m, s.sk, f, envImplicit(s.env, i))
+	})
+}
+
+// EnvVerifier verifies tokens produced by an EnvSigner of the same
+// environment.
+type EnvVerifier struct {
+	pk   ed25519.PublicKey
+	env  string
+	opts options
+}
+
+// NewEnvVerifier creates a verifier bound to the given environment.
+func NewEnvVerifier(pk ed25519.PublicKey, env string, opts ...Option) (*EnvVerifier, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PublicKeySize)
+	}
+	if env == "" {
+		return nil, errors.New("paseto: environment must not be blank")
+	}
+
+	// No error
+	return &EnvVerifier{pk: pk, env: env, opts: newOptions(opts)}, nil
+}
+
+// Verify verifies the token like Verify with the environment bound to the
+// implicit assertion (i). A token signed for another environment fails with
+// ErrInvalidSignature.
+func (v *EnvVerifier) Verify(t string, f, i []byte) ([]byte, error) {
+	return trackBytes(v.opts.observer, observer.OpVerify, func() ([]byte, error) {
+		return Verify(t, v.pk, f, envImplicit(v.env, i))
+	})
+}
+
+// -----------------------------------------------------------------------------
+
+// envImplicit encodes PAE(domain, env, i) so that the environment and the
+// caller implicit assertion can't be confused.
+func envImplicit(env string, i []byte) []byte {
+	return common.PreAuthenticationEncoding([]byte(envImplicitDomain), []byte(env), i)
+}
@@ -0,0 +1,71 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_EnvSigner(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	staging, err := NewEnvSigner(sk, "staging")
+	assert.NoError(t, err)
+	production, err := NewEnvVerifier(pk, "production")
+	assert.NoError(t, err)
+	stagingVerifier, err := NewEnvVerifier(pk, "staging")
+	assert.NoError(t, err)
+
+	m := []byte("{\"sub\":\"user-1\"}")
+	f := []byte("{\"kid\":\"1234\"}")
+	i := []byte("{\"user_id\":\"1234\"}")
+
+	token, err := staging.Sign(m, f, i)
+	assert.NoError(t, err)
+
+	p, err := stagingVerifier.Verify(token, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Cross-environment tokens are rejected
+	_, err = production.Verify(token, f, i)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// The payload is not modified and a plain verification fails
+	_, err = Verify(token, pk, f, i)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// The caller implicit assertion is still bound
+	_, err = stagingVerifier.Verify(token, f, nil)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Invalid arguments
+	_, err = NewEnvSigner(sk, "")
+	assert.Error(t, err)
+	_, err = NewEnvSigner(sk[:32], "staging")
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+	_, err = NewEnvVerifier(pk, "")
+	assert.Error(t, err)
+	_, err = NewEnvVerifier(pk[:16], "staging")
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+}
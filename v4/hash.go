@@ -0,0 +1,33 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"hash"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// newKeyedHash creates the keyed hash used by the KDF and the MAC.
+//
+// BLAKE2b is mandated by the PASETO v4 wire format, so it can't be replaced
+// without breaking interoperability. All BLAKE2b usages are isolated here to
+// keep the dependency on golang.org/x/crypto/blake2b in a single place.
+func newKeyedHash(size int, key []byte) (hash.Hash, error) {
+	return blake2b.New(size, key)
+}
@@ -18,10 +18,12 @@
 package v4
 
 import (
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/base64"
 	"errors"
 	"fmt"
-
-	"golang.org/x/crypto/blake2b"
+	"slices"
 
 	"zntr.io/paseto/internal/common"
 )
@@ -33,7 +35,7 @@ func kdf(key *LocalKey, n []byte) (ek, n2, ak []byte, err error) {
 	}
 
 	// Derive encryption key
-	encKDF, err := blake2b.New(encryptionKDFLength, key[:])
+	encKDF, err := newKeyedHash(encryptionKDFLength, key[:])
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("unable to initialize encryption kdf: %w", err)
 	}
@@ -44,7 +46,7 @@ func kdf(key *LocalKey, n []byte) (ek, n2, ak []byte, err error) {
 	tmp := encKDF.Sum(nil)
 
 	// Derive authentication key
-	authKDF, err := blake2b.New(authenticationKeyLength, key[:])
+	authKDF, err := newKeyedHash(authenticationKeyLength, key[:])
 	if err != nil {
 		return nil, nil, nil, fmt.Errorf("unable to initialize authentication kdf: %w", err)
 	}
@@ -63,7 +65,7 @@ func mac(ak, h, n, c, f, i []byte) ([]byte, error) {
 	preAuth := common.PreAuthenticationEncoding(h, n, c, f, i)
 
 	// Compute MAC
-	mac, err := blake2b.New(macLength, ak)
+	mac, err := newKeyedHash(macLength, ak)
 	if err != nil {
 		return nil, fmt.Errorf("unable to in initialize MAC kdf: %w", err)
 	}
@@ -74,3 +76,52 @@ func mac(ak, h, n, c, f, i []byte) ([]byte, error) {
 	// No error
 	return mac.Sum(nil), nil
 }
+
+// decodeToken checks the token header and returns the decoded body and footer.
+func decodeToken(prefix, input string) (body, footer []byte, err error) {
+	return common.DecodeToken(prefix, input, MaxFooterLength)
+}
+
+// checkFooter compares the expected footer (if any) with the token footer.
+func checkFooter(expected, footer []byte) error {
+	return common.CheckFooter(expected, footer, StrictFooter)
+}
+
+// isSigningKey returns true when the given raw key is an Ed25519 private key
+// (seed || public key).
+func isSigningKey(raw []byte) bool {
+	if len(raw) != ed25519.PrivateKeySize {
+		return false
+	}
+
+	// Derive the public key from the seed part
+	sk := ed25519.NewKeyFromSeed(raw[:ed25519.SeedSize])
+
+	// Compare with the public key part
+	return subtle.ConstantTimeCompare(sk[ed25519.SeedSize:], raw[ed25519.SeedSize:]) == 1
+}
+
+// tokenLen returns the encoded token length for the given header, raw body and
+// raw footer lengths.
+func tokenLen(headerLen, bodyLen, footerLen int) int {
+	l := headerLen + base64.RawURLEncoding.EncodedLen(bodyLen)
+	if footerLen > 0 {
+		l += 1 + base64.RawURLEncoding.EncodedLen(footerLen)
+	}
+
+	return l
+}
+
+// appendToken appends the serialized token `h || base64url(body) [|| "." ||
+// base64url(f)]` to dst.
+func appendToken(dst []byte, prefix string, body, f []byte) []byte {
+	dst = slices.Grow(dst, tokenLen(len(prefix), len(body), len(f)))
+	dst = append(dst, prefix...)
+	dst = base64.RawURLEncoding.AppendEncode(dst, body)
+	if len(f) > 0 {
+		dst = append(dst, '.')
+		dst = base64.RawURLEncoding.AppendEncode(dst, f)
+	}
+
+	return dst
+}
@@ -0,0 +1,252 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"runtime/debug"
+	"time"
+
+	"zntr.io/paseto/claims"
+	"zntr.io/paseto/internal/common"
+	"zntr.io/paseto/observer"
+)
+
+// DefaultTokenLifetime is the token lifetime used when none is configured.
+const DefaultTokenLifetime = 15 * time.Minute
+
+// Issuer encrypts (local) or signs (public) claims as PASETO v4 tokens
+// according to an issuance policy (issuer, lifetime).
+type Issuer struct {
+	key      *LocalKey
+	sk       ed25519.PrivateKey
+	issuer   string
+	lifetime time.Duration
+	now      func() time.Time
+	opts     options
+	nonce    bool
+	footer   claims.Footer
+	err      error
+}
+
+// IssuerOption configures the issuer. The Option values shared with the other
+// helpers, such as WithObserver, are issuer options too.
+type IssuerOption interface {
+	applyIssuer(iss *Issuer)
+}
+
+// NewIssuer creates a local token issuer using the given key.
+func NewIssuer(key *LocalKey, opts ...IssuerOption) *Issuer {
+	return newIssuer(&Issuer{key: key}, opts)
+}
+
+// NewPublicIssuer creates a public token issuer using the given secret key.
+func NewPublicIssuer(sk ed25519.PrivateKey, opts ...IssuerOption) *Issuer {
+	iss := newIssuer(&Issuer{sk: sk}, opts)
+	if len(sk) != ed25519.PrivateKeySize && iss.err == nil {
+		iss.err = fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PrivateKeySize)
+	}
+
+	return iss
+}
+
+// WithTokenLifetime sets the duration between the `iat` and `exp` claims.
+func WithTokenLifetime(d time.Duration) IssuerOption {
+	return issuerOption(func(iss *Issuer) {
+		if d <= 0 {
+			iss.err = errors.New("paseto: token lifetime must be positive")
+			return
+		}
+		iss.lifetime = d
+	})
+}
+
+// WithIssuerClaim sets the `iss` claim value.
+func WithIssuerClaim(issuer string) IssuerOption {
+	return issuerOption(func(iss *Issuer) {
+		iss.issuer = issuer
+	})
+}
+
+// WithRandomNonce adds a random `nonce` claim to each token.
+//
+// Ed25519 signatures are deterministic, two public tokens with the same claims
+// are byte-identical and can be correlated when they are cached or logged.
+// Issued tokens already differ by their random `jti` claim, the nonce is an
+// explicit unlinkability guarantee which doesn't depend on the token
+// identifier policy.
+func WithRandomNonce() IssuerOption {
+	return issuerOption(func(iss *Issuer) {
+		iss.nonce = true
+	})
+}
+
+// WithKeyID sets the `kid` footer claim of the issued tokens.
+func WithKeyID(kid string) IssuerOption {
+	return issuerOption(func(iss *Issuer) {
+		iss.footer.KeyID = kid
+	})
+}
+
+// WithGeneratorTag stamps the producing library in the `gen` footer claim of
+// the issued tokens (`zntr.io/paseto@v1.2.3`), to trace which service version
+// minted a token. The library module version is read from the build
+// information when the tag is empty.
+//
+// The footer is authenticated but not encrypted, don't put secrets in the
+// tag. Verifiers ignore it unless they read the footer.
+func WithGeneratorTag(tag string) IssuerOption {
+	return issuerOption(func(iss *Issuer) {
+		if tag == "" {
+			tag = libraryTag()
+		}
+		iss.footer.Generator = tag
+	})
+}
+
+// Issue creates a token for the given subject with `iat`, `exp`, `iss` and
+// `jti` claims set automatically. Extra claims must not override registered
+// claims.
+func (iss *Issuer) Issue(ctx context.Context, subject string, extra map[string]any) (string, error) {
+	// Check issuer configuration
+	if iss.err != nil {
+		return "", iss.err
+	}
+	if err := ctx.Err(); err != nil {
+		return "", err
+	}
+
+	// Generate a token identifier
+	jti, err := iss.random()
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to generate token identifier: %w", err)
+	}
+
+	// Prepare claims
+	now := iss.now().UTC().Truncate(time.Second)
+	exp := now.Add(iss.lifetime)
+	c := claims.Claims{
+		Issuer:     iss.issuer,
+		Subject:    subject,
+		IssuedAt:   &now,
+		Expiration: &exp,
+		ID:         jti,
+		Custom:     extra,
+	}
+
+	// Add the random nonce
+	if iss.nonce {
+		if _, ok := extra[nonceClaim]; ok {
+			return "", fmt.Errorf("paseto: extra claim %q is reserved by the random nonce", nonceClaim)
+		}
+		nonce, err := iss.random()
+		if err != nil {
+			return "", fmt.Errorf("paseto: unable to generate nonce claim: %w", err)
+		}
+		c.Custom = make(map[string]any, len(extra)+1)
+		for k, v := range extra {
+			c.Custom[k] = v
+		}
+		c.Custom[nonceClaim] = nonce
+	}
+
+	// Encode claims
+	payload, err := json.Marshal(&c)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to encode claims: %w", err)
+	}
+
+	// Encode footer
+	var footer []byte
+	if iss.footer != (claims.Footer{}) {
+		footer, err = json.Marshal(&iss.footer)
+		if err != nil {
+			return "", fmt.Errorf("paseto: unable to encode footer: %w", err)
+		}
+	}
+
+	// Encrypt or sign claims
+	op, seal := observer.OpEncrypt, func() (string, error) {
+		return Encrypt(iss.opts.rand, iss.key, payload, footer, nil)
+	}
+	if iss.sk != nil {
+		op, seal = observer.OpSign, func() (string, error) {
+			return Sign(payload, iss.sk, footer, nil)
+		}
+	}
+
+	return trackString(iss.opts.observer, op, seal)
+}
+
+// -----------------------------------------------------------------------------
+
+const (
+	nonceClaim    = "nonce"
+	libraryModule = "zntr.io/paseto"
+)
+
+type issuerOption func(iss *Issuer)
+
+func (o issuerOption) applyIssuer(iss *Issuer) {
+	o(iss)
+}
+
+func newIssuer(iss *Issuer, opts []IssuerOption) *Issuer {
+	iss.lifetime = DefaultTokenLifetime
+	iss.now = time.Now
+	iss.opts = newOptions(nil)
+	for _, o := range opts {
+		o.applyIssuer(iss)
+	}
+
+	return iss
+}
+
+// random returns 16 random bytes encoded as base64url.
+func (iss *Issuer) random() (string, error) {
+	var raw [16]byte
+	if err := common.ReadRandom(iss.opts.rand, raw[:]); err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(raw[:]), nil
+}
+
+// libraryTag returns the library module path and version from the build
+// information.
+func libraryTag() string {
+	version := "(devel)"
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if bi.Main.Path == libraryModule && bi.Main.Version != "" {
+			version = bi.Main.Version
+		}
+		for _, dep := range bi.Deps {
+			if dep.Path == libraryModule {
+				version = dep.Version
+				break
+			}
+		}
+	}
+
+	return libraryModule + "@" + version
+}
@@ -0,0 +1,172 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"zntr.io/paseto/claims"
+	"zntr.io/paseto/observer"
+)
+
+func Test_Paseto_Issuer_Issue(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	var observed []string
+	iss := NewIssuer(key,
+		WithTokenLifetime(time.Hour),
+		WithIssuerClaim("auth"),
+		WithObserver(observer.Func(func(op, version string, err error, _ time.Duration) {
+			assert.NoError(t, err)
+			observed = append(observed, version+"/"+op)
+		})),
+	)
+	now := time.Date(2022, 1, 1, 0, 0, 0, 500, time.UTC)
+	iss.now = func() time.Time { return now }
+
+	token1, err := iss.Issue(context.Background(), "user-1", map[string]any{"scope": "read"})
+	assert.NoError(t, err)
+	token2, err := iss.Issue(context.Background(), "user-1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []string{"v4/encrypt", "v4/encrypt"}, observed)
+
+	payload, err := Decrypt(key, token1, nil, nil)
+	assert.NoError(t, err)
+	c, err := claims.NewParser().Parse(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "auth", c.Issuer)
+	assert.Equal(t, "user-1", c.Subject)
+	assert.Equal(t, now.Truncate(time.Second), c.IssuedAt.UTC())
+	assert.Equal(t, now.Truncate(time.Second).Add(time.Hour), c.Expiration.UTC())
+	assert.NotEmpty(t, c.ID)
+	assert.Equal(t, "read", c.Custom["scope"])
+
+	// Token identifiers are unique
+	payload, err = Decrypt(key, token2, nil, nil)
+	assert.NoError(t, err)
+	c2, err := claims.NewParser().Parse(payload)
+	assert.NoError(t, err)
+	assert.NotEqual(t, c.ID, c2.ID)
+}
+
+func Test_Paseto_Issuer_Errors(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Invalid lifetime
+	_, err = NewIssuer(key, WithTokenLifetime(0)).Issue(context.Background(), "user-1", nil)
+	assert.Error(t, err)
+
+	// Registered claim override
+	_, err = NewIssuer(key).Issue(context.Background(), "user-1", map[string]any{"exp": "never"})
+	assert.Error(t, err)
+
+	// Nil key
+	_, err = NewIssuer(nil).Issue(context.Background(), "user-1", nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+
+	// Cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = NewIssuer(key).Issue(ctx, "user-1", nil)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Invalid signing key
+	_, err = NewPublicIssuer(nil).Issue(context.Background(), "user-1", nil)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+
+	// Reserved nonce claim
+	_, err = NewIssuer(key, WithRandomNonce()).Issue(context.Background(), "user-1", map[string]any{"nonce": "fixed"})
+	assert.Error(t, err)
+}
+
+func Test_Paseto_Issuer_PublicRandomNonce(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	var observed []string
+	iss := NewPublicIssuer(sk,
+		WithRandomNonce(),
+		WithObserver(observer.Func(func(op, version string, err error, _ time.Duration) {
+			assert.NoError(t, err)
+			observed = append(observed, version+"/"+op)
+		})),
+	)
+
+	extra := map[string]any{"scope": "read"}
+	token1, err := iss.Issue(context.Background(), "user-1", extra)
+	assert.NoError(t, err)
+	token2, err := iss.Issue(context.Background(), "user-1", extra)
+	assert.NoError(t, err)
+	assert.NotEqual(t, token1, token2)
+	assert.Equal(t, []string{"v4/sign", "v4/sign"}, observed)
+
+	// Extra claims are not modified
+	assert.Equal(t, map[string]any{"scope": "read"}, extra)
+
+	payload, err := Verify(token1, pk, nil, nil)
+	assert.NoError(t, err)
+	c, err := claims.NewParser().Parse(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", c.Subject)
+	assert.Equal(t, "read", c.Custom["scope"])
+	assert.Len(t, c.Custom["nonce"], 22)
+}
+
+func Test_Paseto_Issuer_GeneratorTag(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Merged with the key identifier
+	iss := NewIssuer(key, WithKeyID("k1"), WithGeneratorTag("zntr.io/paseto@v1.2.3"))
+	token, err := iss.Issue(context.Background(), "user-1", nil)
+	assert.NoError(t, err)
+
+	var footer claims.Footer
+	payload, err := DecryptFull(key, token, nil, &footer)
+	assert.NoError(t, err)
+	assert.Equal(t, claims.Footer{KeyID: "k1", Generator: "zntr.io/paseto@v1.2.3"}, footer)
+
+	// The payload is unchanged
+	c, err := claims.NewParser().Parse(payload)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", c.Subject)
+
+	// Library version from the build information
+	iss = NewIssuer(key, WithGeneratorTag(""))
+	token, err = iss.Issue(context.Background(), "user-1", nil)
+	assert.NoError(t, err)
+	footer = claims.Footer{}
+	_, err = DecryptFull(key, token, nil, &footer)
+	assert.NoError(t, err)
+	assert.Equal(t, "", footer.KeyID)
+	assert.Regexp(t, `^zntr\.io/paseto@.+$`, footer.Generator)
+
+	// No footer by default
+	token, err = NewIssuer(key).Issue(context.Background(), "user-1", nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, strings.Count(token, "."))
+}
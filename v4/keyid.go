@@ -0,0 +1,108 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+
+	"zntr.io/paseto/claims"
+)
+
+// ErrKeyNotFoundOrInvalidSignature is the uniform error returned by
+// VerifyWithKeyID when the token key identifier is unknown or the signature is
+// invalid.
+var ErrKeyNotFoundOrInvalidSignature = errors.New("paseto: unable to verify token with the declared key")
+
+// KeyIDPublicKey associates a key identifier (footer `kid`) with a public key.
+type KeyIDPublicKey struct {
+	KeyID string
+	Key   ed25519.PublicKey
+}
+
+// zeroPublicKey is used to keep the verification cost identical when no key
+// matches the token key identifier.
+var zeroPublicKey = ed25519.NewKeyFromSeed(make([]byte, ed25519.SeedSize)).Public().(ed25519.PublicKey)
+
+// VerifyWithKeyID verifies a token using the public key identified by the
+// `kid` claim of its JSON footer and returns the message and the key
+// identifier.
+//
+// Threat model: an attacker submitting forged tokens must not be able to
+// enumerate the known key identifiers. The key identifier is compared with all
+// the keys in constant time, a signature verification is always done (with a
+// placeholder key when the identifier is unknown), and a single error is
+// returned whether the key identifier is unknown or the signature is invalid.
+// Malformed tokens are still rejected early, this doesn't depend on the keys.
+func VerifyWithKeyID(t string, keys []KeyIDPublicKey, i []byte) ([]byte, string, error) {
+	// Check arguments
+	if len(keys) == 0 {
+		return nil, "", errors.New("paseto: at least one public key is required")
+	}
+
+	// Decode token
+	raw, footer, err := decodeToken(PublicPrefix, t)
+	if err != nil {
+		return nil, "", err
+	}
+	if len(raw) < ed25519.SignatureSize {
+		return nil, "", ErrInvalidToken
+	}
+	m := raw[:len(raw)-ed25519.SignatureSize]
+	s := raw[len(raw)-ed25519.SignatureSize:]
+
+	// Extract the key identifier (an invalid footer is handled as an unknown
+	// key identifier)
+	var f claims.Footer
+	if len(footer) > 0 {
+		_ = json.Unmarshal(footer, &f)
+	}
+	kid := []byte(f.KeyID)
+
+	// Select the key in constant time
+	pk := make(ed25519.PublicKey, ed25519.PublicKeySize)
+	copy(pk, zeroPublicKey)
+	found := 0
+	for _, entry := range keys {
+		if len(entry.Key) != ed25519.PublicKeySize {
+			continue
+		}
+		match := subtle.ConstantTimeCompare([]byte(entry.KeyID), kid) & (1 ^ found) & boolToInt(len(kid) > 0)
+		subtle.ConstantTimeCopy(match, pk, entry.Key)
+		found |= match
+	}
+
+	// Always verify the signature
+	if err := VerifyDetached(m, s, pk, footer, i); err != nil || found == 0 {
+		return nil, "", ErrKeyNotFoundOrInvalidSignature
+	}
+
+	// No error
+	return m, f.KeyID, nil
+}
+
+// -----------------------------------------------------------------------------
+
+func boolToInt(b bool) int {
+	if b {
+		return 1
+	}
+	return 0
+}
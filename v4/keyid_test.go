@@ -0,0 +1,82 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_Public_VerifyWithKeyID(t *testing.T) {
+	pk1, sk1, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pk2, sk2, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	_, sk3, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	keys := []KeyIDPublicKey{
+		{KeyID: "key-1", Key: pk1},
+		{KeyID: "key-2", Key: pk2},
+	}
+	m := []byte(`{"sub":"test"}`)
+	i := []byte("implicit")
+
+	sign := func(sk ed25519.PrivateKey, f string) string {
+		token, err := Sign(m, sk, []byte(f), i)
+		assert.NoError(t, err)
+		return token
+	}
+
+	testCases := []struct {
+		name    string
+		token   string
+		wantKID string
+		wantErr error
+	}{
+		{name: "key-1", token: sign(sk1, `{"kid":"key-1"}`), wantKID: "key-1"},
+		{name: "key-2", token: sign(sk2, `{"kid":"key-2"}`), wantKID: "key-2"},
+		{name: "unknown kid", token: sign(sk1, `{"kid":"key-3"}`), wantErr: ErrKeyNotFoundOrInvalidSignature},
+		{name: "wrong key", token: sign(sk3, `{"kid":"key-1"}`), wantErr: ErrKeyNotFoundOrInvalidSignature},
+		{name: "swapped kid", token: sign(sk1, `{"kid":"key-2"}`), wantErr: ErrKeyNotFoundOrInvalidSignature},
+		{name: "empty kid", token: sign(sk1, `{"kid":""}`), wantErr: ErrKeyNotFoundOrInvalidSignature},
+		{name: "no footer", token: sign(sk1, ""), wantErr: ErrKeyNotFoundOrInvalidSignature},
+		{name: "invalid footer", token: sign(sk1, "key-1"), wantErr: ErrKeyNotFoundOrInvalidSignature},
+		{name: "local token", token: "v4.local.AAAA", wantErr: ErrWrongPurpose},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got, kid, err := VerifyWithKeyID(testCase.token, keys, i)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, m, got)
+			assert.Equal(t, testCase.wantKID, kid)
+		})
+	}
+
+	_, _, err = VerifyWithKeyID(sign(sk1, `{"kid":"key-1"}`), nil, i)
+	assert.Error(t, err)
+}
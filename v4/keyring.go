@@ -0,0 +1,286 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+
+	"zntr.io/paseto/claims"
+	"zntr.io/paseto/observer"
+)
+
+var (
+	// ErrKeyNotFound is raised when a key identifier is not in the key ring.
+	ErrKeyNotFound = errors.New("paseto: key not found")
+	// ErrNoPrimaryKey is raised when the key ring has no primary key.
+	ErrNoPrimaryKey = errors.New("paseto: key ring has no primary key")
+	// ErrNoMatchingKey is raised when no key of the ring can decrypt the
+	// token, whatever the reason (unknown key identifier or invalid MAC).
+	ErrNoMatchingKey = errors.New("paseto: unable to decrypt token with the key ring")
+	// ErrKeyGraceExpired is raised when the key identified by the token footer
+	// is past its NotAfter date.
+	ErrKeyGraceExpired = errors.New("paseto: key grace period is over")
+)
+
+// KeyEntry describes a key ring entry. Label and CreatedAt are operational
+// metadata, they are never used by the cryptographic operations.
+//
+// NotAfter is the end of the key grace period, it is only enforced by
+// DecryptWithGrace. The zero value means no limit.
+type KeyEntry struct {
+	Key       *LocalKey
+	Label     string
+	CreatedAt time.Time
+	NotAfter  time.Time
+	Primary   bool
+}
+
+// KeyRing holds a set of local keys indexed by key identifier (`kid`) to
+// operate key rotations. The primary key is used for encryption, the other
+// keys are only resolved for decryption. It is safe for concurrent use.
+type KeyRing struct {
+	mu      sync.RWMutex
+	entries map[string]KeyEntry
+	opts    options
+}
+
+// NewKeyRing creates an empty key ring.
+func NewKeyRing(opts ...Option) *KeyRing {
+	return &KeyRing{
+		entries: map[string]KeyEntry{},
+		opts:    newOptions(opts),
+	}
+}
+
+// Add registers the key entry with the given key identifier. When the entry
+// is primary, the previous primary key is demoted.
+func (r *KeyRing) Add(kid string, entry KeyEntry) error {
+	// Check arguments
+	if kid == "" {
+		return errors.New("paseto: key identifier must not be blank")
+	}
+	if entry.Key == nil {
+		return ErrNilKey
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[kid]; ok {
+		return fmt.Errorf("paseto: key %q is already registered", kid)
+	}
+
+	// Demote the previous primary key
+	if entry.Primary {
+		for id, e := range r.entries {
+			if e.Primary {
+				e.Primary = false
+				r.entries[id] = e
+			}
+		}
+	}
+
+	r.entries[kid] = entry
+
+	// No error
+	return nil
+}
+
+// Retire removes the key identified by kid from the ring. Tokens encrypted
+// with this key can't be decrypted anymore. Retiring the primary key leaves
+// the ring without primary key until a new one is added.
+func (r *KeyRing) Retire(kid string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.entries[kid]; !ok {
+		return fmt.Errorf("%w: %q", ErrKeyNotFound, kid)
+	}
+	delete(r.entries, kid)
+
+	// No error
+	return nil
+}
+
+// Primary returns the primary key identifier and entry.
+func (r *KeyRing) Primary() (string, KeyEntry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for kid, e := range r.entries {
+		if e.Primary {
+			return kid, e, nil
+		}
+	}
+
+	return "", KeyEntry{}, ErrNoPrimaryKey
+}
+
+// Lookup returns the entry identified by kid.
+func (r *KeyRing) Lookup(kid string) (KeyEntry, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	e, ok := r.entries[kid]
+	return e, ok
+}
+
+// Encrypt encrypts the message (m) with the primary key and a
+// `{"kid":"..."}` footer identifying it.
+func (r *KeyRing) Encrypt(rand io.Reader, m, i []byte) (string, error) {
+	return trackString(r.opts.observer, observer.OpEncrypt, func() (string, error) {
+		// Select the primary key
+		kid, e, err := r.Primary()
+		if err != nil {
+			return "", err
+		}
+
+		// Prepare footer
+		f, err := json.Marshal(&claims.Footer{KeyID: kid})
+		if err != nil {
+			return "", fmt.Errorf("paseto: unable to encode footer: %w", err)
+		}
+
+		// No error
+		return Encrypt(rand, e.Key, m, f, i)
+	})
+}
+
+// Decrypt decrypts the token and returns the payload, the footer and the
+// identifier of the key which decrypted it.
+//
+// When the footer carries a `kid`, only the matching key is tried. Otherwise,
+// all keys are tried from the newest (CreatedAt) to the oldest, which is the
+// expected situation during a rotation window for tokens issued without key
+// identifier. ErrNoMatchingKey is returned when no key succeeds.
+func (r *KeyRing) Decrypt(token string, i []byte) (payload, footer []byte, kid string, err error) {
+	return r.decrypt(token, i, nil)
+}
+
+// DecryptWithGrace decrypts the token like Decrypt but rejects the keys past
+// their NotAfter date at the given time, even if the token is valid. It
+// allows to retire a key on a schedule: after a rotation the previous key
+// still decrypts the in-flight tokens until its grace period ends.
+//
+// ErrKeyGraceExpired is returned when the footer `kid` identifies an expired
+// key and the token is authentic; a forged token gets ErrNoMatchingKey like an
+// unknown key identifier. Expired keys are skipped when the token has no key
+// identifier.
+func (r *KeyRing) DecryptWithGrace(token string, i []byte, now time.Time) (payload, footer []byte, kid string, err error) {
+	return r.decrypt(token, i, func(e KeyEntry) bool {
+		return e.NotAfter.IsZero() || now.Before(e.NotAfter)
+	})
+}
+
+// -----------------------------------------------------------------------------
+
+// decrypt reports the decryption outcome to the observer.
+func (r *KeyRing) decrypt(token string, i []byte, accept func(KeyEntry) bool) (payload, footer []byte, kid string, err error) {
+	err = observer.Track(r.opts.observer, observer.OpDecrypt, "v4", func() error {
+		var err error
+		payload, footer, kid, err = r.tryDecrypt(token, i, accept)
+		return err
+	})
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	// No error
+	return payload, footer, kid, nil
+}
+
+// tryDecrypt tries the candidate keys accepted by the filter (all keys when
+// nil). A hinted key rejected by the filter is still tried, so that its status
+// is only disclosed for authentic tokens.
+func (r *KeyRing) tryDecrypt(token string, i []byte, accept func(KeyEntry) bool) (payload, footer []byte, kid string, err error) {
+	// Decode token
+	raw, footer, err := decodeToken(LocalPrefix, token)
+	if err != nil {
+		return nil, nil, "", err
+	}
+
+	// Select candidate keys
+	candidates, hinted := r.candidates(footer)
+
+	// Try candidates
+	for _, c := range candidates {
+		accepted := accept == nil || accept(c.entry)
+		if !accepted && !hinted {
+			continue
+		}
+
+		m, err := decryptBody(c.entry.Key, raw, footer, i)
+		switch {
+		case err == nil && !accepted:
+			// The footer is authenticated, the key status can be disclosed
+			clear(m)
+			return nil, nil, "", fmt.Errorf("%w: %q", ErrKeyGraceExpired, c.kid)
+		case err == nil:
+			return m, footer, c.kid, nil
+		case errors.Is(err, ErrInvalidMAC):
+			continue
+		default:
+			return nil, nil, "", err
+		}
+	}
+
+	return nil, nil, "", ErrNoMatchingKey
+}
+
+type keyRingCandidate struct {
+	kid   string
+	entry KeyEntry
+}
+
+// candidates returns the key matching the footer `kid` if any (hinted), or
+// all keys sorted from the newest to the oldest.
+func (r *KeyRing) candidates(footer []byte) (out []keyRingCandidate, hinted bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	// Resolve the key identifier (the footer is not authenticated yet, it is
+	// only used as a hint)
+	var f claims.Footer
+	if len(footer) > 0 && json.Unmarshal(footer, &f) == nil && f.KeyID != "" {
+		e, ok := r.entries[f.KeyID]
+		if !ok {
+			return nil, true
+		}
+		return []keyRingCandidate{{kid: f.KeyID, entry: e}}, true
+	}
+
+	// Fallback to all keys
+	out = make([]keyRingCandidate, 0, len(r.entries))
+	for kid, e := range r.entries {
+		out = append(out, keyRingCandidate{kid: kid, entry: e})
+	}
+	sort.Slice(out, func(a, b int) bool {
+		if !out[a].entry.CreatedAt.Equal(out[b].entry.CreatedAt) {
+			return out[a].entry.CreatedAt.After(out[b].entry.CreatedAt)
+		}
+		return out[a].kid < out[b].kid
+	})
+
+	return out, false
+}
@@ -0,0 +1,200 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/rand"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_KeyRing(t *testing.T) {
+	k1, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k2, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	ring := NewKeyRing()
+	m := []byte("{\"data\":\"this is a secret message\"}")
+
+	// No primary key
+	_, err = ring.Encrypt(rand.Reader, m, nil)
+	assert.ErrorIs(t, err, ErrNoPrimaryKey)
+
+	created := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, ring.Add("key-1", KeyEntry{Key: k1, Label: "initial", CreatedAt: created, Primary: true}))
+	assert.Error(t, ring.Add("key-1", KeyEntry{Key: k2}))
+	assert.Error(t, ring.Add("", KeyEntry{Key: k2}))
+	assert.ErrorIs(t, ring.Add("key-2", KeyEntry{}), ErrNilKey)
+
+	token, err := ring.Encrypt(rand.Reader, m, nil)
+	assert.NoError(t, err)
+	_, err = Decrypt(k1, token, []byte(`{"kid":"key-1"}`), nil)
+	assert.NoError(t, err)
+
+	// Rotate
+	assert.NoError(t, ring.Add("key-2", KeyEntry{Key: k2, Label: "rotated", Primary: true}))
+	kid, e, err := ring.Primary()
+	assert.NoError(t, err)
+	assert.Equal(t, "key-2", kid)
+	assert.True(t, e.Key.Equal(k2))
+
+	old, ok := ring.Lookup("key-1")
+	assert.True(t, ok)
+	assert.False(t, old.Primary)
+	assert.Equal(t, "initial", old.Label)
+	assert.Equal(t, created, old.CreatedAt)
+
+	// Retire
+	assert.NoError(t, ring.Retire("key-1"))
+	assert.ErrorIs(t, ring.Retire("key-1"), ErrKeyNotFound)
+	_, ok = ring.Lookup("key-1")
+	assert.False(t, ok)
+
+	assert.NoError(t, ring.Retire("key-2"))
+	_, _, err = ring.Primary()
+	assert.ErrorIs(t, err, ErrNoPrimaryKey)
+}
+
+func Test_KeyRing_Decrypt(t *testing.T) {
+	k1, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k2, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k3, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	ring := NewKeyRing()
+	now := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	assert.NoError(t, ring.Add("key-1", KeyEntry{Key: k1, CreatedAt: now}))
+	assert.NoError(t, ring.Add("key-2", KeyEntry{Key: k2, CreatedAt: now.Add(time.Hour), Primary: true}))
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	i := []byte("{\"test-vector\":\"key-ring\"}")
+
+	withKID, err := ring.Encrypt(rand.Reader, m, i)
+	assert.NoError(t, err)
+	oldWithoutKID, err := Encrypt(rand.Reader, k1, m, nil, i)
+	assert.NoError(t, err)
+	oldWithKID, err := Encrypt(rand.Reader, k1, m, []byte(`{"kid":"key-1"}`), i)
+	assert.NoError(t, err)
+	unknownKID, err := Encrypt(rand.Reader, k1, m, []byte(`{"kid":"key-3"}`), i)
+	assert.NoError(t, err)
+	wrongKID, err := Encrypt(rand.Reader, k1, m, []byte(`{"kid":"key-2"}`), i)
+	assert.NoError(t, err)
+	foreign, err := Encrypt(rand.Reader, k3, m, nil, i)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name       string
+		token      string
+		wantKID    string
+		wantFooter []byte
+		wantErr    error
+	}{
+		{name: "primary", token: withKID, wantKID: "key-2", wantFooter: []byte(`{"kid":"key-2"}`)},
+		{name: "old key with kid", token: oldWithKID, wantKID: "key-1", wantFooter: []byte(`{"kid":"key-1"}`)},
+		{name: "old key without kid", token: oldWithoutKID, wantKID: "key-1"},
+		{name: "unknown kid", token: unknownKID, wantErr: ErrNoMatchingKey},
+		{name: "wrong kid", token: wrongKID, wantErr: ErrNoMatchingKey},
+		{name: "foreign key", token: foreign, wantErr: ErrNoMatchingKey},
+		{name: "invalid token", token: "v4.public.AAAA", wantErr: ErrWrongPurpose},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got, footer, kid, err := ring.Decrypt(testCase.token, i)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, m, got)
+			assert.Equal(t, testCase.wantKID, kid)
+			assert.Equal(t, testCase.wantFooter, footer)
+		})
+	}
+}
+
+func Test_KeyRing_DecryptWithGrace(t *testing.T) {
+	k1, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k2, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// key-1 is rotated, its grace period ends one day after the rotation
+	rotation := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	ring := NewKeyRing()
+	assert.NoError(t, ring.Add("key-1", KeyEntry{Key: k1, CreatedAt: rotation.Add(-24 * time.Hour), NotAfter: rotation.Add(24 * time.Hour)}))
+	assert.NoError(t, ring.Add("key-2", KeyEntry{Key: k2, CreatedAt: rotation, Primary: true}))
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+
+	oldWithKID, err := Encrypt(rand.Reader, k1, m, []byte(`{"kid":"key-1"}`), nil)
+	assert.NoError(t, err)
+	oldWithoutKID, err := Encrypt(rand.Reader, k1, m, nil, nil)
+	assert.NoError(t, err)
+	current, err := ring.Encrypt(rand.Reader, m, nil)
+	assert.NoError(t, err)
+
+	// Forged token pointing to the expired key
+	forger, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	forged, err := Encrypt(rand.Reader, forger, m, []byte(`{"kid":"key-1"}`), nil)
+	assert.NoError(t, err)
+	forgedUnknown, err := Encrypt(rand.Reader, forger, m, []byte(`{"kid":"key-0"}`), nil)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		token   string
+		now     time.Time
+		wantKID string
+		wantErr error
+	}{
+		{name: "old key within grace", token: oldWithKID, now: rotation.Add(time.Hour), wantKID: "key-1"},
+		{name: "old key without kid within grace", token: oldWithoutKID, now: rotation.Add(time.Hour), wantKID: "key-1"},
+		{name: "old key at the end of grace", token: oldWithKID, now: rotation.Add(24 * time.Hour), wantErr: ErrKeyGraceExpired},
+		{name: "old key after grace", token: oldWithKID, now: rotation.Add(48 * time.Hour), wantErr: ErrKeyGraceExpired},
+		{name: "old key without kid after grace", token: oldWithoutKID, now: rotation.Add(48 * time.Hour), wantErr: ErrNoMatchingKey},
+		{name: "forged token with expired kid", token: forged, now: rotation.Add(48 * time.Hour), wantErr: ErrNoMatchingKey},
+		{name: "forged token with unknown kid", token: forgedUnknown, now: rotation.Add(48 * time.Hour), wantErr: ErrNoMatchingKey},
+		{name: "current key without limit", token: current, now: rotation.Add(365 * 24 * time.Hour), wantKID: "key-2"},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got, _, kid, err := ring.DecryptWithGrace(testCase.token, nil, testCase.now)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				assert.Nil(t, got)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, m, got)
+			assert.Equal(t, testCase.wantKID, kid)
+		})
+	}
+
+	// Decrypt doesn't enforce the grace period
+	_, _, kid, err := ring.Decrypt(oldWithKID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "key-1", kid)
+}
@@ -18,20 +18,24 @@
 package v4
 
 import (
-	"bytes"
 	"crypto/subtle"
-	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"golang.org/x/crypto/chacha20"
+
+	"zntr.io/paseto/internal/common"
 )
 
 // GenerateLocalKey generates a key for local encryption.
+//
+// It reads exactly KeyLength bytes from r.
 func GenerateLocalKey(r io.Reader) (*LocalKey, error) {
 	var key LocalKey
-	if _, err := io.ReadFull(r, key[:]); err != nil {
+	if err := common.ReadRandom(r, key[:]); err != nil {
 		return nil, fmt.Errorf("paseto: unable to generate a random key: %w", err)
 	}
 
@@ -43,7 +47,10 @@ func GenerateLocalKey(r io.Reader) (*LocalKey, error) {
 func LocalKeyFromSeed(seed []byte) (*LocalKey, error) {
 	// Check minimum seed size.
 	if len(seed) < KeyLength {
-		return nil, fmt.Errorf("paseto: invalid seed length, it must be %d bytes long at least", KeyLength)
+		return nil, fmt.Errorf("%w, seed must be %d bytes long at least", ErrInvalidKeyLength, KeyLength)
+	}
+	if isSigningKey(seed) {
+		return nil, ErrKeyMisuse
 	}
 
 	// Copy data from seed.
@@ -54,132 +61,405 @@ func LocalKeyFromSeed(seed []byte) (*LocalKey, error) {
 	return &key, nil
 }
 
-// PASETO v4 symmetric encryption primitive.
-// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#encrypt
-func Encrypt(r io.Reader, key *LocalKey, m, f, i []byte) (string, error) {
-	// Check arguments
-	if key == nil {
-		return "", errors.New("paseto: key is nil")
-	}
-	if len(key) != KeyLength {
-		return "", fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+// LocalKeyFromHex creates a local key from its hex encoded form. The decoded
+// key must be exactly KeyLength bytes long.
+func LocalKeyFromHex(s string) (*LocalKey, error) {
+	raw, err := common.DecodeHexKey(s, KeyLength)
+	if err != nil {
+		return nil, err
 	}
 
-	rawPrefix := []byte(LocalPrefix)
-
-	// Pre-allocate body
-	body := make([]byte, nonceLength+len(m), nonceLength+len(m)+macLength)
+	return LocalKeyFromSeed(raw)
+}
 
-	// Create random seed
-	if _, err := io.ReadFull(r, body[:nonceLength]); err != nil {
-		return "", fmt.Errorf("paseto: unable to generate random seed: %w", err)
+// LocalKeyFromBase64 creates a local key from its base64 encoded form, with
+// the standard or URL-safe alphabet, padded or not. The decoded key must be
+// exactly KeyLength bytes long.
+func LocalKeyFromBase64(s string) (*LocalKey, error) {
+	raw, err := common.DecodeBase64Key(s, KeyLength)
+	if err != nil {
+		return nil, err
 	}
 
-	// Derive keys from seed and secret key
-	ek, n2, ak, err := kdf(key, body[:nonceLength])
-	if err != nil {
-		return "", fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
+	return LocalKeyFromSeed(raw)
+}
+
+// DeriveLocalKey derives a sub-key from the root key for the given context
+// (tenant, recipient identifier).
+//
+// It is a KDF, not a PASERK operation: the sub-key is
+// BLAKE2b-256(key = root, message = "paseto-v4-derived-local-key" || context).
+// Sub-keys of distinct contexts are independent, disclosing one of them doesn't
+// expose the root key nor the other sub-keys.
+func DeriveLocalKey(root *LocalKey, context []byte) (*LocalKey, error) {
+	// Check arguments
+	if root == nil {
+		return nil, ErrNilKey
+	}
+	if len(context) == 0 {
+		return nil, errors.New("paseto: key derivation context must not be blank")
 	}
 
-	// Prepare XChaCha20 stream cipher (nonce > 24bytes => XChacha)
-	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n2)
+	// Derive the sub-key
+	h, err := newKeyedHash(KeyLength, root[:])
 	if err != nil {
-		return "", fmt.Errorf("paseto: unable to initialize XChaCha20 cipher: %w", err)
+		return nil, fmt.Errorf("paseto: unable to initialize key derivation: %w", err)
 	}
+	h.Write([]byte(derivedKeyDomain))
+	h.Write(context)
 
-	// Encrypt the payload
-	ciph.XORKeyStream(body[nonceLength:], m)
+	var key LocalKey
+	copy(key[:], h.Sum(nil))
 
-	// Compute MAC
-	t, err := mac(ak, rawPrefix, body[:nonceLength], body[nonceLength:], f, i)
+	// No error
+	return &key, nil
+}
+
+// PASETO v4 symmetric encryption primitive.
+// https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#encrypt
+//
+// It reads exactly 32 bytes (the nonce) from r.
+func Encrypt(r io.Reader, key *LocalKey, m, f, i []byte) (string, error) {
+	// Encrypt into a new buffer
+	token, err := AppendEncrypt(nil, r, key, m, f, i)
 	if err != nil {
-		return "", fmt.Errorf("paseto: unable to compute MAC: %w", err)
+		return "", err
 	}
 
-	// Serialize final token
-	// h || base64url(n || c || t)
-	body = append(body, t...)
+	// No error
+	return string(token), nil
+}
 
-	// Encode body as RawURLBase64
-	tokenLen := base64.RawURLEncoding.EncodedLen(len(body))
-	footerLen := 0
-	if len(f) > 0 {
-		footerLen = base64.RawURLEncoding.EncodedLen(len(f)) + 1
-		tokenLen += footerLen
+// AppendEncrypt encrypts the message (m) like Encrypt and appends the token to
+// dst, returning the extended buffer. dst is grown at most once, use
+// EncryptedLen to size it beforehand.
+func AppendEncrypt(dst []byte, r io.Reader, key *LocalKey, m, f, i []byte) ([]byte, error) {
+	// Create random seed
+	var n [nonceLength]byte
+	if err := common.ReadRandom(r, n[:]); err != nil {
+		return nil, fmt.Errorf("paseto: unable to generate random seed: %w", err)
 	}
 
-	final := make([]byte, len(LocalPrefix)+tokenLen)
-	copy(final, rawPrefix)
-	base64.RawURLEncoding.Encode(final[9:], body)
+	return appendEncrypt(dst, n[:], key, m, f, i)
+}
 
-	// Assemble final token
-	if len(f) > 0 {
-		final[9+tokenLen-footerLen] = '.'
-		// Encode footer as RawURLBase64
-		base64.RawURLEncoding.Encode(final[9+tokenLen-footerLen+1:], f)
+// EncryptTo encrypts the message (m) like Encrypt and writes the token to w,
+// skipping the string conversion. It returns the number of bytes written.
+func EncryptTo(w io.Writer, r io.Reader, key *LocalKey, m, f, i []byte) (int, error) {
+	// Encrypt into a buffer sized for the token
+	token, err := AppendEncrypt(make([]byte, 0, EncryptedLen(len(m), len(f))), r, key, m, f, i)
+	if err != nil {
+		return 0, err
 	}
 
 	// No error
-	return string(final), nil
+	return w.Write(token)
+}
+
+// EncryptWithNonce encrypts the message (m) using the given nonce instead of a
+// random one.
+//
+// It is intended for testing and conformance checks against the published test
+// vectors only. Reusing a nonce breaks the confidentiality of the tokens, it
+// must never be used in production code.
+func EncryptWithNonce(key *LocalKey, nonce, m, f, i []byte) (string, error) {
+	return encrypt(nonce, key, m, f, i)
+}
+
+// EncryptJSONFooter encrypts the message (m) like Encrypt with a footer
+// marshaled from the given value, so that the footer is always well-formed
+// JSON. The footer must encode to a JSON object, ErrFooterNotJSON is raised
+// otherwise, a nil footer produces a token without footer.
+//
+// Use DecryptFull or DecryptFooterMap to decode the footer on the other side.
+func EncryptJSONFooter(r io.Reader, key *LocalKey, m []byte, footer any, i []byte) (string, error) {
+	// Encode the footer
+	var f []byte
+	if footer != nil {
+		var err error
+		f, err = json.Marshal(footer)
+		if err != nil {
+			return "", fmt.Errorf("paseto: unable to encode footer: %w", err)
+		}
+		if len(f) == 0 || f[0] != '{' {
+			return "", ErrFooterNotJSON
+		}
+	}
+
+	// Delegate to the raw footer implementation
+	return Encrypt(r, key, m, f, i)
+}
+
+// EncryptedLen returns the exact length of the token produced by Encrypt for a
+// message of msgLen bytes and a footer of footerLen bytes.
+func EncryptedLen(msgLen, footerLen int) int {
+	return tokenLen(len(LocalPrefix), nonceLength+msgLen+macLength, footerLen)
 }
 
 // PASETO v4 symmetric decryption primitive
 // https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#decrypt
+//
+// The MAC is compared in constant time and the payload is only decrypted once
+// the MAC is valid. Returning early on a MAC failure doesn't need to be
+// hidden: the only information it discloses is the token validity, which the
+// returned error already discloses, and no secret-dependent data is processed
+// before the comparison.
 func Decrypt(key *LocalKey, input string, f, i []byte) ([]byte, error) {
 	// Check arguments
 	if key == nil {
-		return nil, errors.New("paseto: key is nil")
+		return nil, ErrNilKey
 	}
 	if len(key) != KeyLength {
-		return nil, fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, KeyLength)
 	}
 	if input == "" {
 		return nil, errors.New("paseto: input is blank")
 	}
 
-	rawToken := []byte(input)
+	// Decode token
+	raw, footer, err := decodeToken(LocalPrefix, input)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check token header
-	if !bytes.HasPrefix(rawToken, []byte(LocalPrefix)) {
-		return nil, errors.New("paseto: invalid token")
+	// Check footer usage
+	if err := checkFooter(f, footer); err != nil {
+		return nil, err
 	}
 
-	// Trim prefix
-	rawToken = rawToken[len(LocalPrefix):]
+	// Decrypt the body in place
+	return decryptBody(key, raw, f, i)
+}
+
+// DecryptPrefix decrypts a PASETO v4 local token like Decrypt but only
+// returns the first n bytes of the payload (or the whole payload if it is
+// shorter).
+//
+// The MAC is still verified over the whole ciphertext before anything is
+// decrypted, so the whole token is decoded: only the decryption of the payload
+// tail is skipped. It helps when a streaming parser only needs the payload
+// prefix, it doesn't reduce the memory needed to hold the token.
+func DecryptPrefix(key *LocalKey, input string, f, i []byte, n int) ([]byte, error) {
+	// Check arguments
+	if key == nil {
+		return nil, ErrNilKey
+	}
+	if n < 0 {
+		return nil, errors.New("paseto: prefix length must not be negative")
+	}
+
+	// Decode token
+	raw, footer, err := decodeToken(LocalPrefix, input)
+	if err != nil {
+		return nil, err
+	}
 
 	// Check footer usage
-	if len(f) > 0 {
-		// Split the footer and the body
-		footerIdx := bytes.Index(rawToken, []byte("."))
-		if footerIdx == 0 {
-			return nil, errors.New("paseto: invalid token, footer is missing but expected")
-		}
+	if err := checkFooter(f, footer); err != nil {
+		return nil, err
+	}
 
-		// Decode footer
-		footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawToken[footerIdx+1:])))
-		if _, err := base64.RawURLEncoding.Decode(footer, rawToken[footerIdx+1:]); err != nil {
-			return nil, fmt.Errorf("paseto: invalid token, footer has invalid encoding: %w", err)
-		}
+	// Decrypt the body prefix in place
+	return decryptBodyPrefix(key, raw, f, i, n)
+}
+
+// DecryptRaw decrypts an already decoded PASETO v4 local token body.
+//
+// It skips the header check and the base64 decoding steps of Decrypt. The
+// expected rawBody layout is `n (32 bytes) || c (*) || t (32 bytes)` where n is
+// the nonce, c the ciphertext and t the MAC. f is the decoded footer bound to
+// the token (not the base64 encoded value) and i the implicit assertion.
+//
+// rawBody is not modified.
+func DecryptRaw(key *LocalKey, rawBody, f, i []byte) ([]byte, error) {
+	// Check arguments
+	if key == nil {
+		return nil, ErrNilKey
+	}
+
+	// Copy the body to keep the input untouched
+	raw := make([]byte, len(rawBody))
+	copy(raw, rawBody)
+
+	// Decrypt the body copy
+	return decryptBody(key, raw, f, i)
+}
+
+// DecryptFull decrypts a PASETO v4 local token without knowing its footer in
+// advance.
+//
+// The footer read from the token is bound to the MAC, so it is only trusted
+// once the token is authenticated. If footerDst is not nil and the token has a
+// footer, the footer is JSON decoded into footerDst after the MAC check.
+func DecryptFull(key *LocalKey, input string, i []byte, footerDst any) ([]byte, error) {
+	// Check arguments
+	if key == nil {
+		return nil, ErrNilKey
+	}
+	if input == "" {
+		return nil, errors.New("paseto: input is blank")
+	}
+
+	// Decode token
+	raw, footer, err := decodeToken(LocalPrefix, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Decrypt the body using the token footer
+	m, err := decryptBody(key, raw, footer, i)
+	if err != nil {
+		return nil, err
+	}
 
-		// Compare footer
-		if subtle.ConstantTimeCompare(f, footer) == 0 {
-			return nil, errors.New("paseto: invalid token, footer mismatch")
+	// Decode the authenticated footer
+	if footerDst != nil && len(footer) > 0 {
+		if err := json.Unmarshal(footer, footerDst); err != nil {
+			return nil, fmt.Errorf("paseto: unable to decode footer: %w", err)
 		}
+	}
 
-		// Continue without footer
-		rawToken = rawToken[:footerIdx]
+	// No error
+	return m, nil
+}
+
+// DecryptFooterMap decrypts a PASETO v4 local token like DecryptFull and
+// returns its footer as a map. The footer map is empty when the token has no
+// footer, ErrFooterNotJSON is raised when the footer is not a JSON object.
+func DecryptFooterMap(key *LocalKey, input string, i []byte) ([]byte, map[string]any, error) {
+	// Check arguments
+	if key == nil {
+		return nil, nil, ErrNilKey
+	}
+	if input == "" {
+		return nil, nil, errors.New("paseto: input is blank")
 	}
 
 	// Decode token
-	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawToken)))
-	if _, err := base64.RawURLEncoding.Decode(raw, rawToken); err != nil {
-		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
+	raw, footer, err := decodeToken(LocalPrefix, input)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decrypt the body using the token footer
+	m, err := decryptBody(key, raw, footer, i)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Decode the authenticated footer
+	footerMap := map[string]any{}
+	if len(footer) > 0 {
+		if err := json.Unmarshal(footer, &footerMap); err != nil || footerMap == nil {
+			return nil, nil, ErrFooterNotJSON
+		}
+	}
+
+	// No error
+	return m, footerMap, nil
+}
+
+// ExtractNonce returns the nonce of a PASETO v4 local token without decrypting
+// it. The token structure is validated but no cryptographic operation is done,
+// so the token is not authenticated.
+//
+// The nonce is not secret, it is meant to help audits (correlation, nonce
+// reuse detection).
+func ExtractNonce(input string) ([]byte, error) {
+	// Decode token
+	raw, _, err := decodeToken(LocalPrefix, input)
+	if err != nil {
+		return nil, err
+	}
+
+	// Extract the nonce
+	n, _, _, err := common.SplitLocalBody(raw, nonceLength, macLength)
+	if err != nil {
+		return nil, err
+	}
+
+	// No error
+	return n, nil
+}
+
+// IsLocal returns true when the token has the `v4.local.` header. It only checks
+// the header, the token is neither decoded nor authenticated.
+func IsLocal(token string) bool {
+	return strings.HasPrefix(token, LocalPrefix)
+}
+
+// -----------------------------------------------------------------------------
+
+func encrypt(nonce []byte, key *LocalKey, m, f, i []byte) (string, error) {
+	// Encrypt into a new buffer
+	token, err := appendEncrypt(nil, nonce, key, m, f, i)
+	if err != nil {
+		return "", err
+	}
+
+	// No error
+	return string(token), nil
+}
+
+func appendEncrypt(dst, nonce []byte, key *LocalKey, m, f, i []byte) ([]byte, error) {
+	// Check arguments
+	if key == nil {
+		return nil, ErrNilKey
+	}
+	if len(key) != KeyLength {
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, KeyLength)
+	}
+	if len(nonce) != nonceLength {
+		return nil, fmt.Errorf("paseto: invalid nonce length, it must be %d bytes long", nonceLength)
+	}
+
+	rawPrefix := []byte(LocalPrefix)
+
+	// Pre-allocate body
+	body := make([]byte, nonceLength+len(m), nonceLength+len(m)+macLength)
+	copy(body, nonce)
+
+	// Derive keys from seed and secret key
+	ek, n2, ak, err := kdf(key, body[:nonceLength])
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
 	}
 
+	// Prepare XChaCha20 stream cipher (nonce > 24bytes => XChacha)
+	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n2)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to initialize XChaCha20 cipher: %w", err)
+	}
+
+	// Encrypt the payload
+	ciph.XORKeyStream(body[nonceLength:], m)
+
+	// Compute MAC
+	t, err := mac(ak, rawPrefix, body[:nonceLength], body[nonceLength:], f, i)
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to compute MAC: %w", err)
+	}
+
+	// Serialize final token
+	// h || base64url(n || c || t)
+	body = append(body, t...)
+
+	// No error
+	return appendToken(dst, LocalPrefix, body, f), nil
+}
+
+func decryptBody(key *LocalKey, raw, f, i []byte) ([]byte, error) {
+	return decryptBodyPrefix(key, raw, f, i, -1)
+}
+
+// decryptBodyPrefix decrypts at most limit bytes of the payload, a negative
+// limit decrypts the whole payload.
+func decryptBodyPrefix(key *LocalKey, raw, f, i []byte, limit int) ([]byte, error) {
 	// Extract components
-	n := raw[:nonceLength]
-	t := raw[len(raw)-macLength:]
-	c := raw[nonceLength : len(raw)-macLength]
+	n, c, t, err := common.SplitLocalBody(raw, nonceLength, macLength)
+	if err != nil {
+		return nil, err
+	}
 
 	// Derive keys from seed and secret key
 	ek, n2, ak, err := kdf(key, n)
@@ -193,9 +473,9 @@ func Decrypt(key *LocalKey, input string, f, i []byte) ([]byte, error) {
 		return nil, fmt.Errorf("paseto: unable to compute MAC: %w", err)
 	}
 
-	// Time-constant compare MAC
+	// Time-constant compare MAC (never decrypt unauthenticated ciphertext)
 	if subtle.ConstantTimeCompare(t, t2) == 0 {
-		return nil, errors.New("paseto: invalid pre-authentication header")
+		return nil, common.AuthError(ErrInvalidMAC, i)
 	}
 
 	// Prepare XChaCha20 stream cipher
@@ -205,6 +485,9 @@ func Decrypt(key *LocalKey, input string, f, i []byte) ([]byte, error) {
 	}
 
 	// Decrypt the payload
+	if limit >= 0 && limit < len(c) {
+		c = c[:limit]
+	}
 	ciph.XORKeyStream(c, c)
 
 	// No error
@@ -19,9 +19,14 @@ package v4
 
 import (
 	"bytes"
+	"crypto/ed25519"
 	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
+	"io"
+	"strings"
 	"testing"
+	"testing/iotest"
 
 	"github.com/stretchr/testify/assert"
 )
@@ -145,7 +150,7 @@ func Test_Paseto_LocalVector(t *testing.T) {
 			assert.NoError(t, err)
 
 			// Encrypt
-			token, err := Encrypt(bytes.NewReader(n), key, testCase.payload, testCase.footer, testCase.implicitAssertion)
+			token, err := EncryptWithNonce(key, n, testCase.payload, testCase.footer, testCase.implicitAssertion)
 			if (err != nil) != testCase.expectFail {
 				t.Errorf("error during the encrypt call, error = %v, wantErr %v", err, testCase.expectFail)
 				return
@@ -189,6 +194,886 @@ func Test_Paseto_Local_EncryptDecrypt(t *testing.T) {
 	assert.Equal(t, m, p)
 }
 
+func Test_Paseto_Local_FooterSegments(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+
+	withFooter, err := Encrypt(rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+	withoutFooter, err := Encrypt(rand.Reader, key, m, nil, nil)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name   string
+		token  string
+		footer []byte
+	}{
+		{name: "extra segment", token: withFooter + ".Zm9vdGVy", footer: f},
+		{name: "trailing dot", token: withFooter + ".", footer: f},
+		{name: "empty footer segment", token: withoutFooter + ".", footer: nil},
+		{name: "missing footer", token: withoutFooter, footer: f},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Decrypt(key, testCase.token, testCase.footer, nil)
+			assert.Error(t, err)
+		})
+	}
+}
+
+func Test_Paseto_Local_DecryptRaw(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"4-E-7\"}")
+
+	token, err := Encrypt(rand.Reader, key, m, f, i)
+	assert.NoError(t, err)
+
+	// Decode the body (n || c || t)
+	rawBody, err := base64.RawURLEncoding.DecodeString(strings.Split(strings.TrimPrefix(token, LocalPrefix), ".")[0])
+	assert.NoError(t, err)
+	original := append([]byte{}, rawBody...)
+
+	p, err := DecryptRaw(key, rawBody, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+	assert.Equal(t, original, rawBody)
+
+	// Footer is part of the MAC
+	_, err = DecryptRaw(key, rawBody, nil, i)
+	assert.Error(t, err)
+
+	// Truncated body
+	_, err = DecryptRaw(key, rawBody[:nonceLength+macLength-1], f, i)
+	assert.Error(t, err)
+}
+
+func Test_Paseto_Local_KeyErrors(t *testing.T) {
+	_, err := Encrypt(rand.Reader, nil, []byte("message"), nil, nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+
+	_, err = Decrypt(nil, LocalPrefix+"AAAA", nil, nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+
+	_, err = LocalKeyFromSeed(make([]byte, KeyLength-1))
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+}
+
+func Test_Paseto_Local_KeyFromEncoded(t *testing.T) {
+	expected, err := LocalKeyFromSeed([]byte("0123456789abcdef0123456789abcdef"))
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name    string
+		decode  func(string) (*LocalKey, error)
+		input   string
+		wantErr bool
+		errIs   error
+	}{
+		{name: "hex", decode: LocalKeyFromHex, input: "3031323334353637383961626364656630313233343536373839616263646566"},
+		{name: "hex with newline", decode: LocalKeyFromHex, input: "3031323334353637383961626364656630313233343536373839616263646566\n"},
+		{name: "hex invalid", decode: LocalKeyFromHex, input: "zz", wantErr: true},
+		{name: "hex short", decode: LocalKeyFromHex, input: "30313233343536373839616263646566303132333435363738396162636465", wantErr: true, errIs: ErrInvalidKeyLength},
+		{name: "hex long", decode: LocalKeyFromHex, input: "303132333435363738396162636465663031323334353637383961626364656667", wantErr: true, errIs: ErrInvalidKeyLength},
+		{name: "base64", decode: LocalKeyFromBase64, input: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY="},
+		{name: "base64 raw", decode: LocalKeyFromBase64, input: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZWY"},
+		{name: "base64 invalid", decode: LocalKeyFromBase64, input: "!!!!", wantErr: true},
+		{name: "base64 short", decode: LocalKeyFromBase64, input: "MDEyMzQ1Njc4OWFiY2RlZjAxMjM0NTY3ODlhYmNkZQ==", wantErr: true, errIs: ErrInvalidKeyLength},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			key, err := testCase.decode(testCase.input)
+			if testCase.wantErr {
+				assert.Error(t, err)
+				assert.Nil(t, key)
+				if testCase.errIs != nil {
+					assert.ErrorIs(t, err, testCase.errIs)
+				}
+				return
+			}
+			assert.NoError(t, err)
+			assert.True(t, expected.Equal(key))
+		})
+	}
+}
+
+func Test_Paseto_Local_Padded(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"1234567890\"}")
+
+	token, err := EncryptPadded(rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+	assert.True(t, strings.HasSuffix(token, "="))
+
+	// Padded tokens are not accepted by the standard decryption
+	_, err = Decrypt(key, token, f, nil)
+	assert.Error(t, err)
+
+	p, err := DecryptPadded(key, token, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Unpadded tokens are rejected by the legacy decryption
+	token, err = Encrypt(rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+	_, err = DecryptPadded(key, token, f, nil)
+	assert.Error(t, err)
+}
+
+func Test_Paseto_Local_Compressed(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"" + strings.Repeat("this is a secret message ", 100) + "\"}")
+	f := []byte("{\"kid\":\"1234567890\"}")
+	i := []byte("{\"test-vector\":\"compressed\"}")
+
+	token, err := EncryptCompressed(rand.Reader, key, m, f, i)
+	assert.NoError(t, err)
+	assert.Less(t, len(token), EncryptedLen(len(m), len(f)))
+
+	p, err := DecryptCompressed(key, token, f, i, len(m))
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Compressed tokens are not accepted by the standard decryption
+	_, err = Decrypt(key, token, f, i)
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+
+	// Standard tokens are not accepted by the compressed decryption
+	plain, err := Encrypt(rand.Reader, key, m, f, i)
+	assert.NoError(t, err)
+	_, err = DecryptCompressed(key, plain, f, i, len(m))
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+
+	// Decompression bomb
+	_, err = DecryptCompressed(key, token, f, i, len(m)-1)
+	assert.ErrorIs(t, err, ErrDecompressedTooLarge)
+
+	_, err = DecryptCompressed(key, token, f, i, 0)
+	assert.Error(t, err)
+}
+
+func Test_Paseto_Local_EmptyMessage(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"heartbeat\"}")
+
+	for _, m := range [][]byte{nil, {}} {
+		for _, footer := range [][]byte{nil, f} {
+			token, err := Encrypt(rand.Reader, key, m, footer, i)
+			assert.NoError(t, err)
+
+			p, err := Decrypt(key, token, footer, i)
+			assert.NoError(t, err)
+			assert.Empty(t, p)
+		}
+	}
+}
+
+func Test_Paseto_Local_DecryptFull(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"4-E-7\"}")
+
+	token, err := Encrypt(rand.Reader, key, m, f, i)
+	assert.NoError(t, err)
+
+	var footer struct {
+		KeyID string `json:"kid"`
+	}
+	p, err := DecryptFull(key, token, i, &footer)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+	assert.Equal(t, "zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN", footer.KeyID)
+
+	// Footer destination is optional
+	p, err = DecryptFull(key, token, i, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Implicit assertion is still required
+	_, err = DecryptFull(key, token, nil, nil)
+	assert.Error(t, err)
+
+	// Footer is part of the MAC
+	parts := strings.Split(token, ".")
+	parts[3] = base64.RawURLEncoding.EncodeToString([]byte("{\"kid\":\"attacker\"}"))
+	footer.KeyID = ""
+	_, err = DecryptFull(key, strings.Join(parts, "."), i, &footer)
+	assert.Error(t, err)
+	assert.Empty(t, footer.KeyID)
+
+	// Non JSON footer
+	token, err = Encrypt(rand.Reader, key, m, []byte("raw-footer"), i)
+	assert.NoError(t, err)
+	_, err = DecryptFull(key, token, i, &footer)
+	assert.Error(t, err)
+	p, err = DecryptFull(key, token, i, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+}
+
+func Test_Paseto_Local_BinaryFooter(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	i := []byte("{\"user_id\":\"1234\"}")
+
+	// Non UTF-8 footer (flags), including bytes which could be mistaken for
+	// JSON or string delimiters
+	f := []byte{0x00, 0xff, 0xfe, 0x80, '{', '"', 0x01, 0xc3}
+
+	token, err := Encrypt(rand.Reader, key, m, f, i)
+	assert.NoError(t, err)
+
+	// The footer is transmitted as is
+	parts := strings.Split(token, ".")
+	assert.Len(t, parts, 4)
+	rawFooter, err := base64.RawURLEncoding.DecodeString(parts[3])
+	assert.NoError(t, err)
+	assert.Equal(t, f, rawFooter)
+
+	// Round trip with the expected footer
+	p, err := Decrypt(key, token, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// A single bit flip in the footer is detected
+	tampered := bytes.Clone(f)
+	tampered[1] ^= 0x01
+	_, err = Decrypt(key, token, tampered, i)
+	assert.Error(t, err)
+
+	// JSON footer helpers report the footer as not JSON
+	_, err = DecryptFull(key, token, i, &map[string]any{})
+	assert.ErrorContains(t, err, "unable to decode footer")
+	p, err = DecryptFull(key, token, i, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+	_, _, err = DecryptFooterMap(key, token, i)
+	assert.ErrorIs(t, err, ErrFooterNotJSON)
+	_, err = RouteKey(token)
+	assert.ErrorIs(t, err, ErrFooterNotJSON)
+
+	// The footer is left untouched
+	assert.Equal(t, []byte{0x00, 0xff, 0xfe, 0x80, '{', '"', 0x01, 0xc3}, f)
+}
+
+func Test_Paseto_Local_EncryptJSONFooter(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	i := []byte("{\"user_id\":\"1234\"}")
+
+	type footer struct {
+		KeyID string `json:"kid"`
+	}
+
+	// Round trip through DecryptFull
+	token, err := EncryptJSONFooter(rand.Reader, key, m, footer{KeyID: "k1\"}"}, i)
+	assert.NoError(t, err)
+	var decoded footer
+	p, err := DecryptFull(key, token, i, &decoded)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+	assert.Equal(t, "k1\"}", decoded.KeyID)
+
+	// Round trip through DecryptFooterMap
+	_, footerMap, err := DecryptFooterMap(key, token, i)
+	assert.NoError(t, err)
+	assert.Equal(t, map[string]any{"kid": "k1\"}"}, footerMap)
+
+	// Nil footer
+	token, err = EncryptJSONFooter(rand.Reader, key, m, nil, i)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, strings.Count(token, ".")+1)
+
+	// Not a JSON object
+	_, err = EncryptJSONFooter(rand.Reader, key, m, []string{"kid"}, i)
+	assert.ErrorIs(t, err, ErrFooterNotJSON)
+	_, err = EncryptJSONFooter(rand.Reader, key, m, (*footer)(nil), i)
+	assert.ErrorIs(t, err, ErrFooterNotJSON)
+
+	// Not encodable
+	_, err = EncryptJSONFooter(rand.Reader, key, m, map[string]any{"fn": func() {}}, i)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrFooterNotJSON)
+}
+
+func Test_Paseto_Local_DecryptFooterMap(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	i := []byte("{\"user_id\":\"1234\"}")
+
+	testCases := []struct {
+		name    string
+		footer  []byte
+		want    map[string]any
+		wantErr error
+	}{
+		{name: "no footer", want: map[string]any{}},
+		{name: "json footer", footer: []byte("{\"kid\":\"1234\",\"v\":2}"), want: map[string]any{"kid": "1234", "v": float64(2)}},
+		{name: "raw footer", footer: []byte("raw-footer"), wantErr: ErrFooterNotJSON},
+		{name: "json array footer", footer: []byte("[1,2]"), wantErr: ErrFooterNotJSON},
+		{name: "json null footer", footer: []byte("null"), wantErr: ErrFooterNotJSON},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			token, err := Encrypt(rand.Reader, key, m, testCase.footer, i)
+			assert.NoError(t, err)
+
+			p, footer, err := DecryptFooterMap(key, token, i)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, m, p)
+			assert.Equal(t, testCase.want, footer)
+		})
+	}
+
+	// Authentication failures are reported first
+	token, err := Encrypt(rand.Reader, key, m, []byte("raw-footer"), i)
+	assert.NoError(t, err)
+	_, _, err = DecryptFooterMap(key, token, nil)
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+	_, _, err = DecryptFooterMap(nil, token, i)
+	assert.ErrorIs(t, err, ErrNilKey)
+}
+
+func Test_Paseto_Local_KeyMisuse(t *testing.T) {
+	_, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Signing key used as local key seed
+	_, err = LocalKeyFromSeed(sk)
+	assert.ErrorIs(t, err, ErrKeyMisuse)
+
+	// Arbitrary seeds are still accepted
+	_, err = LocalKeyFromSeed(bytes.Repeat([]byte{0x01}, ed25519.PrivateKeySize))
+	assert.NoError(t, err)
+
+	// Local key used as signing key
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	_, err = Sign([]byte("message"), key[:], nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+}
+
+func Test_Paseto_Local_MalformedToken(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+
+	token, err := Encrypt(rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+
+	body := strings.Split(strings.TrimPrefix(token, LocalPrefix), ".")[0]
+	footer := strings.Split(token, ".")[3]
+
+	testCases := []struct {
+		name  string
+		token string
+	}{
+		{name: "wrong header", token: "v2.local." + body + "." + footer},
+		{name: "truncated body", token: LocalPrefix + body[:10] + "." + footer},
+		{name: "truncated body with invalid length", token: LocalPrefix + body[:4*(len(body)/8)+1] + "." + footer},
+		{name: "invalid body encoding", token: LocalPrefix + "!" + body[1:] + "." + footer},
+		{name: "invalid footer encoding", token: LocalPrefix + body + "." + footer + "!"},
+		{name: "empty footer", token: LocalPrefix + body + "."},
+		{name: "too many segments", token: token + ".extra"},
+		{name: "leading whitespace", token: " " + token},
+		{name: "trailing whitespace", token: token + " "},
+		{name: "trailing newline", token: token + "\n"},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Decrypt(key, testCase.token, f, nil)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+}
+
+func Test_Paseto_Local_ShortBody(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Valid base64 bodies shorter than the nonce and the MAC
+	for n := 1; n < nonceLength+macLength; n++ {
+		token := LocalPrefix + base64.RawURLEncoding.EncodeToString(make([]byte, n))
+		assert.NotPanics(t, func() {
+			_, err := Decrypt(key, token, nil, nil)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+			_, err = DecryptPrefix(key, token, nil, nil, 1)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+			_, err = ExtractNonce(token)
+			assert.ErrorIs(t, err, ErrInvalidToken)
+		})
+	}
+
+	// Minimal length is structurally valid
+	token := LocalPrefix + base64.RawURLEncoding.EncodeToString(make([]byte, nonceLength+macLength))
+	_, err = Decrypt(key, token, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+}
+
+func Test_Paseto_Local_MACOnlyBody(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	token, err := Encrypt(rand.Reader, key, []byte("message"), nil, nil)
+	assert.NoError(t, err)
+	raw, err := base64.RawURLEncoding.DecodeString(strings.TrimPrefix(token, LocalPrefix))
+	assert.NoError(t, err)
+
+	// Body made of the MAC only (no nonce)
+	macOnly := LocalPrefix + base64.RawURLEncoding.EncodeToString(raw[len(raw)-macLength:])
+	_, err = Decrypt(key, macOnly, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	assert.NotErrorIs(t, err, ErrInvalidMAC)
+
+	// Body missing a single byte of the nonce
+	truncated := LocalPrefix + base64.RawURLEncoding.EncodeToString(raw[len(raw)-macLength-nonceLength+1:])
+	_, err = Decrypt(key, truncated, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func Test_Paseto_Local_ShortRandom(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Short random source
+	_, err = Encrypt(bytes.NewReader(make([]byte, nonceLength-1)), key, []byte("payload"), nil, nil)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+	_, err = GenerateLocalKey(bytes.NewReader(make([]byte, KeyLength-1)))
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+
+	// Random source without progress
+	_, err = Encrypt(iotest.TimeoutReader(bytes.NewReader(make([]byte, nonceLength))), key, []byte("payload"), nil, nil)
+	assert.NoError(t, err)
+	_, err = Encrypt(emptyReader{}, key, []byte("payload"), nil, nil)
+	assert.ErrorIs(t, err, io.ErrNoProgress)
+}
+
+func Test_Paseto_Local_EncryptWithNonce(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	nonce := bytes.Repeat([]byte{0x01}, nonceLength)
+
+	// Deterministic output
+	token1, err := EncryptWithNonce(key, nonce, m, nil, nil)
+	assert.NoError(t, err)
+	token2, err := EncryptWithNonce(key, nonce, m, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, token1, token2)
+
+	p, err := Decrypt(key, token1, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Invalid nonce lengths
+	_, err = EncryptWithNonce(key, nonce[1:], m, nil, nil)
+	assert.Error(t, err)
+	_, err = EncryptWithNonce(key, append(nonce, 0x01), m, nil, nil)
+	assert.Error(t, err)
+	_, err = EncryptWithNonce(key, nil, m, nil, nil)
+	assert.Error(t, err)
+}
+
+func Test_Paseto_Local_EncryptedLen(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	for msgLen := 0; msgLen < 8; msgLen++ {
+		for _, footerLen := range []int{0, 1, 2, 3, 4, 5} {
+			token, err := Encrypt(rand.Reader, key, make([]byte, msgLen), bytes.Repeat([]byte{'f'}, footerLen), nil)
+			assert.NoError(t, err)
+			assert.Equal(t, len(token), EncryptedLen(msgLen, footerLen), "msgLen=%d footerLen=%d", msgLen, footerLen)
+		}
+	}
+}
+
+func Test_Paseto_Local_ExtractNonce(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	nonce := bytes.Repeat([]byte{0x42}, nonceLength)
+	token, err := EncryptWithNonce(key, nonce, []byte("message"), []byte("footer"), nil)
+	assert.NoError(t, err)
+
+	n, err := ExtractNonce(token)
+	assert.NoError(t, err)
+	assert.Equal(t, nonce, n)
+
+	// Invalid tokens
+	_, err = ExtractNonce("v4.public." + strings.TrimPrefix(token, LocalPrefix))
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = ExtractNonce(LocalPrefix + "AAAA")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = ExtractNonce("")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+}
+
+func Test_Paseto_StrictFooter(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+
+	localToken, err := Encrypt(rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+	publicToken, err := Sign(m, sk, f, nil)
+	assert.NoError(t, err)
+
+	// Permissive mode relies on the authentication failure
+	_, err = Decrypt(key, localToken, nil, nil)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken)
+	_, err = Verify(publicToken, pk, nil, nil)
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, ErrInvalidToken)
+
+	StrictFooter = true
+	defer func() { StrictFooter = false }()
+
+	// Strict mode rejects the unexpected footer explicitly
+	_, err = Decrypt(key, localToken, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, err = Verify(publicToken, pk, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidToken)
+
+	// Expected footers are still accepted
+	p, err := Decrypt(key, localToken, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+	p, err = Verify(publicToken, pk, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Tokens without footer are still accepted
+	localToken, err = Encrypt(rand.Reader, key, m, nil, nil)
+	assert.NoError(t, err)
+	p, err = Decrypt(key, localToken, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+}
+
+func Test_Paseto_Local_Tampered(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	otherKey, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"tampered\"}")
+
+	token, err := Encrypt(rand.Reader, key, m, f, i)
+	assert.NoError(t, err)
+
+	// v.local.body.footer
+	parts := strings.Split(token, ".")
+	body, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+
+	flip := func(offset int) string {
+		raw := append([]byte{}, body...)
+		raw[offset] ^= 0x01
+		return parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(raw) + "." + parts[3]
+	}
+	alteredFooter := []byte("{\"kid\":\"attacker\"}")
+	alteredToken := parts[0] + "." + parts[1] + "." + parts[2] + "." + base64.RawURLEncoding.EncodeToString(alteredFooter)
+
+	testCases := []struct {
+		name    string
+		key     *LocalKey
+		token   string
+		footer  []byte
+		ia      []byte
+		wantErr error
+	}{
+		{name: "nonce bit flip", key: key, token: flip(0), footer: f, ia: i, wantErr: ErrInvalidMAC},
+		{name: "ciphertext bit flip", key: key, token: flip(nonceLength), footer: f, ia: i, wantErr: ErrInvalidMAC},
+		{name: "tag bit flip", key: key, token: flip(len(body) - 1), footer: f, ia: i, wantErr: ErrInvalidMAC},
+		{name: "altered footer", key: key, token: alteredToken, footer: alteredFooter, ia: i, wantErr: ErrInvalidMAC},
+		{name: "footer mismatch", key: key, token: alteredToken, footer: f, ia: i, wantErr: ErrInvalidToken},
+		{name: "wrong implicit assertion", key: key, token: token, footer: f, ia: []byte("{\"test-vector\":\"other\"}"), wantErr: ErrInvalidMAC},
+		{name: "missing implicit assertion", key: key, token: token, footer: f, ia: nil, wantErr: ErrInvalidMAC},
+		{name: "wrong key", key: otherKey, token: token, footer: f, ia: i, wantErr: ErrInvalidMAC},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Decrypt(testCase.key, testCase.token, testCase.footer, testCase.ia)
+			assert.ErrorIs(t, err, testCase.wantErr)
+		})
+	}
+
+	// Untampered token
+	p, err := Decrypt(key, token, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+}
+
+func Test_Paseto_Local_FooterTooLarge(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := bytes.Repeat([]byte{'f'}, 1025)
+
+	token, err := Encrypt(rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+
+	// Default limit
+	p, err := Decrypt(key, token, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	MaxFooterLength = 1024
+	defer func() { MaxFooterLength = DefaultMaxFooterLength }()
+
+	_, err = Decrypt(key, token, f, nil)
+	assert.ErrorIs(t, err, ErrFooterTooLarge)
+
+	// Footer at the limit
+	token, err = Encrypt(rand.Reader, key, m, f[:1024], nil)
+	assert.NoError(t, err)
+	p, err = Decrypt(key, token, f[:1024], nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+}
+
+func Test_Paseto_Purpose(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	token, err := Encrypt(rand.Reader, key, []byte("message"), nil, nil)
+	assert.NoError(t, err)
+
+	assert.True(t, IsLocal(token))
+	assert.False(t, IsLocal("v4.public."+strings.TrimPrefix(token, LocalPrefix)))
+	assert.False(t, IsLocal("v2.local."+strings.TrimPrefix(token, LocalPrefix)))
+	assert.False(t, IsLocal(""))
+	assert.False(t, IsPublic(token))
+	assert.True(t, IsPublic(PublicPrefix+"AAAA"))
+	assert.False(t, IsPublic(""))
+}
+
+func Test_LocalKey_Equal(t *testing.T) {
+	k1, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k2, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k1Copy := *k1
+
+	assert.True(t, k1.Equal(k1))
+	assert.True(t, k1.Equal(&k1Copy))
+	assert.False(t, k1.Equal(k2))
+	assert.False(t, k1.Equal(nil))
+	assert.False(t, (*LocalKey)(nil).Equal(k1))
+	assert.True(t, (*LocalKey)(nil).Equal(nil))
+}
+
+func Test_Paseto_Local_AppendEncrypt(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+
+	// Append to an existing content
+	buf := make([]byte, 0, 7+EncryptedLen(len(m), len(f)))
+	buf = append(buf, "Bearer "...)
+	out, err := AppendEncrypt(buf, rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, "Bearer ", string(out[:7]))
+	assert.Equal(t, cap(buf), cap(out), "buffer must not be reallocated")
+
+	p, err := Decrypt(key, string(out[7:]), f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Errors don't return a buffer
+	out, err = AppendEncrypt(buf, rand.Reader, nil, m, f, nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+	assert.Nil(t, out)
+}
+
+func Test_Paseto_Local_EncryptTo(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+
+	var buf bytes.Buffer
+	n, err := EncryptTo(&buf, rand.Reader, key, m, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, EncryptedLen(len(m), len(f)), n)
+	assert.Equal(t, n, buf.Len())
+
+	p, err := Decrypt(key, buf.String(), f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Nothing is written on error
+	buf.Reset()
+	_, err = EncryptTo(&buf, rand.Reader, nil, m, f, nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+	assert.Zero(t, buf.Len())
+}
+
+func Test_Paseto_Local_DecryptPrefix(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"sub\":\"user-1\",\"data\":\"" + strings.Repeat("a", 1024) + "\"}")
+	f := []byte("{\"kid\":\"1234567890\"}")
+	i := []byte("{\"test-vector\":\"prefix\"}")
+
+	token, err := Encrypt(rand.Reader, key, m, f, i)
+	assert.NoError(t, err)
+
+	testCases := []struct {
+		name string
+		n    int
+		want []byte
+	}{
+		{name: "empty", n: 0, want: []byte{}},
+		{name: "prefix", n: 16, want: m[:16]},
+		{name: "exact", n: len(m), want: m},
+		{name: "longer", n: len(m) + 10, want: m},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			got, err := DecryptPrefix(key, token, f, i, testCase.n)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+
+	// The MAC is checked over the whole ciphertext
+	raw, err := base64.RawURLEncoding.DecodeString(strings.Split(token, ".")[2])
+	assert.NoError(t, err)
+	raw[len(raw)-macLength-1] ^= 0x01
+	tampered := LocalPrefix + base64.RawURLEncoding.EncodeToString(raw) + "." + base64.RawURLEncoding.EncodeToString(f)
+	_, err = DecryptPrefix(key, tampered, f, i, 16)
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+
+	_, err = DecryptPrefix(key, token, f, i, -1)
+	assert.Error(t, err)
+	_, err = DecryptPrefix(nil, token, f, i, 16)
+	assert.ErrorIs(t, err, ErrNilKey)
+}
+
+func Test_Paseto_EmptyFooterAndImplicit(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a message\"}")
+	nonce := bytes.Repeat([]byte{0x01}, nonceLength)
+	empties := [][]byte{nil, {}, []byte("")}
+
+	// Reference tokens
+	wantLocal, err := EncryptWithNonce(key, nonce, m, nil, nil)
+	assert.NoError(t, err)
+	wantPublic, err := Sign(m, sk, nil, nil)
+	assert.NoError(t, err)
+
+	for _, f := range empties {
+		for _, i := range empties {
+			local, err := EncryptWithNonce(key, nonce, m, f, i)
+			assert.NoError(t, err)
+			assert.Equal(t, wantLocal, local)
+
+			public, err := Sign(m, sk, f, i)
+			assert.NoError(t, err)
+			assert.Equal(t, wantPublic, public)
+
+			// Empty values are interchangeable on the consumer side
+			for _, f2 := range empties {
+				for _, i2 := range empties {
+					p, err := Decrypt(key, local, f2, i2)
+					assert.NoError(t, err)
+					assert.Equal(t, m, p)
+
+					p, err = Verify(public, pk, f2, i2)
+					assert.NoError(t, err)
+					assert.Equal(t, m, p)
+				}
+			}
+		}
+	}
+}
+
+func Test_DeriveLocalKey(t *testing.T) {
+	var root LocalKey
+	_, err := hex.Decode(root[:], []byte("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f"))
+	assert.NoError(t, err)
+
+	k1, err := DeriveLocalKey(&root, []byte("tenant-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, "865b65f4f26cd92dba43bb03b55d83eb1415b27f9e1e67f12fc2604d33c339d0", hex.EncodeToString(k1[:]))
+
+	// Deterministic
+	again, err := DeriveLocalKey(&root, []byte("tenant-1"))
+	assert.NoError(t, err)
+	assert.True(t, k1.Equal(again))
+
+	// Context separation
+	k2, err := DeriveLocalKey(&root, []byte("tenant-2"))
+	assert.NoError(t, err)
+	assert.False(t, k1.Equal(k2))
+	assert.False(t, k1.Equal(&root))
+
+	// Tokens are not interchangeable
+	token, err := Encrypt(rand.Reader, k1, []byte("message"), nil, nil)
+	assert.NoError(t, err)
+	_, err = Decrypt(k2, token, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidMAC)
+
+	// Invalid arguments
+	_, err = DeriveLocalKey(nil, []byte("tenant-1"))
+	assert.ErrorIs(t, err, ErrNilKey)
+	_, err = DeriveLocalKey(&root, nil)
+	assert.Error(t, err)
+}
+
 // -----------------------------------------------------------------------------
 
 func benchmarkEncrypt(key *LocalKey, m, f, i []byte, b *testing.B) {
@@ -240,3 +1125,32 @@ func Benchmark_Paseto_Decrypt(b *testing.B) {
 
 	benchmarkDecrypt(&key, t, f, i, b)
 }
+
+func Benchmark_Paseto_DecryptRaw(b *testing.B) {
+	keyRaw := [32]byte{}
+	_, err := hex.Decode(keyRaw[:], []byte("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f"))
+	assert.NoError(b, err)
+	key := LocalKey(keyRaw)
+
+	rawBody, err := base64.RawURLEncoding.DecodeString("32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t5uvqQbMGlLLNYBc7A6_x7oqnpUK5WLvj24eE4DVPDZjw")
+	assert.NoError(b, err)
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"4-E-8\"}")
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for n := 0; n < b.N; n++ {
+		_, err := DecryptRaw(&key, rawBody, f, i)
+		if err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// emptyReader is a misbehaving reader which never returns data nor error.
+type emptyReader struct{}
+
+func (emptyReader) Read([]byte) (int, error) {
+	return 0, nil
+}
@@ -0,0 +1,104 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"fmt"
+
+	"zntr.io/paseto/internal/common"
+)
+
+// SignMulti signs the message (m) like Sign with a list of implicit context
+// pieces (extra) encoded as distinct PAE pieces after the footer, instead of
+// a single implicit assertion. As PAE is length-prefixed, the pieces can't be
+// confused with each other, there is no need to canonicalize them into a
+// single byte blob.
+//
+// NON STANDARD: the PASETO specification defines a single implicit assertion.
+// With no extra piece or exactly one, the token is identical to a Sign token
+// with an empty or the given implicit assertion. With more pieces, the token
+// can only be verified by VerifyMulti.
+func SignMulti(m []byte, sk ed25519.PrivateKey, f []byte, extra ...[]byte) (string, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PrivateKeySize)
+	}
+
+	// Sign protected content
+	sig := ed25519.Sign(sk, multiPAE(m, f, extra))
+
+	// Prepare content
+	body := make([]byte, 0, len(m)+ed25519.SignatureSize)
+	body = append(body, m...)
+	body = append(body, sig...)
+
+	// No error
+	return string(appendToken(nil, PublicPrefix, body, f)), nil
+}
+
+// VerifyMulti verifies a token produced by SignMulti with the same implicit
+// context pieces (extra), in the same order.
+func VerifyMulti(t string, pk ed25519.PublicKey, f []byte, extra ...[]byte) ([]byte, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PublicKeySize)
+	}
+
+	// Decode token
+	m, s, err := decodePublicToken(t, f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check signature
+	if !ed25519.Verify(pk, multiPAE(m, f, extra), s) {
+		return nil, common.AuthError(ErrInvalidSignature, multiImplicit(extra))
+	}
+
+	// No error
+	return m, nil
+}
+
+// -----------------------------------------------------------------------------
+
+// multiPAE returns PAE(h, m, f, extra...), an empty extra list is encoded as a
+// single empty implicit assertion to match the standard construction.
+func multiPAE(m, f []byte, extra [][]byte) []byte {
+	if len(extra) == 0 {
+		extra = [][]byte{nil}
+	}
+
+	pieces := make([][]byte, 0, 3+len(extra))
+	pieces = append(pieces, []byte(PublicPrefix), m, f)
+	pieces = append(pieces, extra...)
+
+	return common.PreAuthenticationEncoding(pieces...)
+}
+
+// multiImplicit returns the first non-empty piece, only used to decide
+// whether the implicit assertion mismatch hint applies.
+func multiImplicit(extra [][]byte) []byte {
+	for _, e := range extra {
+		if len(e) > 0 {
+			return e
+		}
+	}
+
+	return nil
+}
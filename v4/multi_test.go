@@ -0,0 +1,86 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_SignMulti(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := []byte("{\"kid\":\"1234\"}")
+	i := []byte("{\"user_id\":\"1234\"}")
+
+	// Standard construction with zero or one piece
+	token, err := SignMulti(m, sk, f)
+	assert.NoError(t, err)
+	expected, err := Sign(m, sk, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, token)
+
+	token, err = SignMulti(m, sk, f, i)
+	assert.NoError(t, err)
+	expected, err = Sign(m, sk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, token)
+	p, err := VerifyMulti(token, pk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Multiple pieces
+	token, err = SignMulti(m, sk, f, []byte("tenant-1"), []byte("device-1"))
+	assert.NoError(t, err)
+	p, err = VerifyMulti(token, pk, f, []byte("tenant-1"), []byte("device-1"))
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	testCases := []struct {
+		name  string
+		extra [][]byte
+	}{
+		{name: "swapped", extra: [][]byte{[]byte("device-1"), []byte("tenant-1")}},
+		{name: "concatenated", extra: [][]byte{[]byte("tenant-1device-1")}},
+		{name: "shifted boundary", extra: [][]byte{[]byte("tenant-1d"), []byte("evice-1")}},
+		{name: "missing", extra: [][]byte{[]byte("tenant-1")}},
+		{name: "extra", extra: [][]byte{[]byte("tenant-1"), []byte("device-1"), nil}},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := VerifyMulti(token, pk, f, testCase.extra...)
+			assert.ErrorIs(t, err, ErrInvalidSignature)
+		})
+	}
+
+	// Not verifiable with the standard construction
+	_, err = Verify(token, pk, f, []byte("tenant-1device-1"))
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Invalid keys
+	_, err = SignMulti(m, sk[:32], f)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+	_, err = VerifyMulti(token, pk[:16], f)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+}
@@ -0,0 +1,106 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/rand"
+	"io"
+
+	"zntr.io/paseto/observer"
+)
+
+// Option configures the helpers: Issuer, KeyRing, EnvSigner, EnvVerifier,
+// SequentialEncryptor and VerifyStream.
+type Option func(*options)
+
+// WithObserver sets the observer notified of each operation run by the
+// helper.
+func WithObserver(o observer.Observer) Option {
+	return func(opts *options) {
+		if o != nil {
+			opts.observer = o
+		}
+	}
+}
+
+// WithRandomSource sets the random source used by the Issuer (nonce and token
+// identifier) and the SequentialEncryptor (random part of the nonce),
+// crypto/rand by default. Helpers taking the random source as an argument
+// ignore it.
+func WithRandomSource(r io.Reader) Option {
+	return func(opts *options) {
+		if r != nil {
+			opts.rand = r
+		}
+	}
+}
+
+// -----------------------------------------------------------------------------
+
+type options struct {
+	observer observer.Observer
+	rand     io.Reader
+}
+
+func newOptions(opts []Option) options {
+	out := options{
+		observer: observer.Nop,
+		rand:     rand.Reader,
+	}
+	for _, o := range opts {
+		o(&out)
+	}
+
+	return out
+}
+
+func (o Option) applyIssuer(iss *Issuer) {
+	o(&iss.opts)
+}
+
+// trackString runs fn and reports its outcome to the observer.
+func trackString(o observer.Observer, op string, fn func() (string, error)) (string, error) {
+	var out string
+	err := observer.Track(o, op, "v4", func() error {
+		var err error
+		out, err = fn()
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+
+	// No error
+	return out, nil
+}
+
+// trackBytes runs fn and reports its outcome to the observer.
+func trackBytes(o observer.Observer, op string, fn func() ([]byte, error)) ([]byte, error) {
+	var out []byte
+	err := observer.Track(o, op, "v4", func() error {
+		var err error
+		out, err = fn()
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// No error
+	return out, nil
+}
@@ -0,0 +1,147 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"context"
+	"crypto/ed25519"
+	"crypto/rand"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"zntr.io/paseto/observer"
+)
+
+type observation struct {
+	op, version string
+	err         error
+	dur         time.Duration
+}
+
+func recordObservations(got *[]observation) Option {
+	return WithObserver(observer.Func(func(op, version string, err error, dur time.Duration) {
+		*got = append(*got, observation{op: op, version: version, err: err, dur: dur})
+	}))
+}
+
+func assertObservation(t *testing.T, got observation, op string, wantErr error) {
+	t.Helper()
+
+	assert.Equal(t, op, got.op)
+	assert.Equal(t, "v4", got.version)
+	if wantErr != nil {
+		assert.ErrorIs(t, got.err, wantErr)
+	} else {
+		assert.NoError(t, got.err)
+	}
+	assert.Greater(t, got.dur, time.Duration(0))
+}
+
+func Test_Paseto_Observer_KeyRing(t *testing.T) {
+	var got []observation
+	ring := NewKeyRing(recordObservations(&got))
+
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	assert.NoError(t, ring.Add("key-1", KeyEntry{Key: key, Primary: true}))
+
+	token, err := ring.Encrypt(rand.Reader, []byte("message"), nil)
+	assert.NoError(t, err)
+	_, _, _, err = ring.Decrypt(token, nil)
+	assert.NoError(t, err)
+	_, _, _, err = ring.DecryptWithGrace(token, []byte("other"), time.Now())
+	assert.ErrorIs(t, err, ErrNoMatchingKey)
+
+	assert.Len(t, got, 3)
+	assertObservation(t, got[0], observer.OpEncrypt, nil)
+	assertObservation(t, got[1], observer.OpDecrypt, nil)
+	assertObservation(t, got[2], observer.OpDecrypt, ErrNoMatchingKey)
+}
+
+func Test_Paseto_Observer_Env(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	var got []observation
+	signer, err := NewEnvSigner(sk, "staging", recordObservations(&got))
+	assert.NoError(t, err)
+	verifier, err := NewEnvVerifier(pk, "production", recordObservations(&got))
+	assert.NoError(t, err)
+
+	token, err := signer.Sign([]byte("message"), nil, nil)
+	assert.NoError(t, err)
+	_, err = verifier.Verify(token, nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	assert.Len(t, got, 2)
+	assertObservation(t, got[0], observer.OpSign, nil)
+	assertObservation(t, got[1], observer.OpVerify, ErrInvalidSignature)
+}
+
+func Test_Paseto_Observer_Sequential(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	var got []observation
+	_, err = NewSequentialEncryptor(key, recordObservations(&got)).Encrypt([]byte("message"), nil, nil)
+	assert.NoError(t, err)
+	_, err = NewSequentialEncryptor(nil, recordObservations(&got)).Encrypt([]byte("message"), nil, nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+
+	assert.Len(t, got, 2)
+	assertObservation(t, got[0], observer.OpEncrypt, nil)
+	assertObservation(t, got[1], observer.OpEncrypt, ErrNilKey)
+}
+
+func Test_Paseto_Observer_VerifyStream(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	token, err := Sign([]byte("message"), sk, nil, nil)
+	assert.NoError(t, err)
+
+	var got []observation
+	err = VerifyStream(strings.NewReader(token+"\n\n"+token+"x\n"), pk, nil, func(_, _ []byte, _ error) {}, recordObservations(&got))
+	assert.NoError(t, err)
+
+	assert.Len(t, got, 2)
+	assertObservation(t, got[0], observer.OpVerify, nil)
+	assert.Equal(t, observer.OpVerify, got[1].op)
+	assert.Error(t, got[1].err)
+}
+
+func Test_Paseto_Observer_SharedOption(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// The same option configures the issuer and the helpers
+	var got []observation
+	opt := recordObservations(&got)
+
+	_, err = NewIssuer(key, opt).Issue(context.Background(), "user-1", nil)
+	assert.NoError(t, err)
+	_, err = NewSequentialEncryptor(key, opt).Encrypt([]byte("message"), nil, nil)
+	assert.NoError(t, err)
+
+	assert.Len(t, got, 2)
+	assertObservation(t, got[0], observer.OpEncrypt, nil)
+	assertObservation(t, got[1], observer.OpEncrypt, nil)
+}
@@ -0,0 +1,94 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+
+	"zntr.io/paseto/internal/common"
+)
+
+// EncryptPadded encrypts a message like Encrypt but encodes the token segments
+// using padded base64url.
+//
+// NON STANDARD: PASETO mandates unpadded base64url, the produced tokens are
+// not spec compliant. This is a compatibility shim for legacy consumers only.
+func EncryptPadded(r io.Reader, key *LocalKey, m, f, i []byte) (string, error) {
+	// Encrypt with the standard encoding
+	token, err := Encrypt(r, key, m, f, i)
+	if err != nil {
+		return "", err
+	}
+
+	// No error
+	return reencodeSegments(token, base64.RawURLEncoding, base64.URLEncoding)
+}
+
+// DecryptPadded decrypts a token produced by EncryptPadded.
+//
+// NON STANDARD: PASETO mandates unpadded base64url, the accepted tokens are
+// not spec compliant. This is a compatibility shim for legacy producers only.
+func DecryptPadded(key *LocalKey, input string, f, i []byte) ([]byte, error) {
+	// Convert to the standard encoding
+	token, err := reencodeSegments(input, base64.URLEncoding, base64.RawURLEncoding)
+	if err != nil {
+		return nil, err
+	}
+
+	// No error
+	return Decrypt(key, token, f, i)
+}
+
+// -----------------------------------------------------------------------------
+
+func reencodeSegments(token string, from, to *base64.Encoding) (string, error) {
+	// Check token header
+	if err := common.CheckHeader([]byte(token), LocalPrefix); err != nil {
+		return "", err
+	}
+
+	// Split the footer and the body
+	rawBody, rawFooter, err := common.SplitToken([]byte(token[len(LocalPrefix):]))
+	if err != nil {
+		return "", err
+	}
+
+	// Check footer size before decoding
+	if err := common.CheckFooterLength(rawFooter, MaxFooterLength); err != nil {
+		return "", err
+	}
+
+	// Re-encode segments
+	body, err := from.DecodeString(string(rawBody))
+	if err != nil {
+		return "", fmt.Errorf("%w body: %v", ErrInvalidToken, err)
+	}
+	out := LocalPrefix + to.EncodeToString(body)
+	if len(rawFooter) > 0 {
+		footer, err := from.DecodeString(string(rawFooter))
+		if err != nil {
+			return "", fmt.Errorf("%w, footer has invalid encoding: %v", ErrInvalidToken, err)
+		}
+		out += "." + to.EncodeToString(footer)
+	}
+
+	// No error
+	return out, nil
+}
@@ -0,0 +1,147 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+
+	"zntr.io/paseto/claims"
+)
+
+const (
+	paserkPublicPrefix  = "k4.public."
+	paserkLocalPrefix   = "k4.local."
+	paserkLocalIDHeader = "k4.lid."
+	paserkIDLength      = 33
+)
+
+// LocalKeyID returns the PASERK local key identifier (`k4.lid.`) of the given
+// key.
+// https://github.com/paseto-standard/paserk/blob/master/operations/ID.md
+func LocalKeyID(key *LocalKey) (string, error) {
+	// Check arguments
+	if key == nil {
+		return "", ErrNilKey
+	}
+
+	// Serialize the key as PASERK
+	p := paserkLocalPrefix + base64.RawURLEncoding.EncodeToString(key[:])
+
+	// BLAKE2b-264(h || p)
+	h, err := newKeyedHash(paserkIDLength, nil)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to initialize key identifier hash: %w", err)
+	}
+	h.Write([]byte(paserkLocalIDHeader))
+	h.Write([]byte(p))
+
+	// No error
+	return paserkLocalIDHeader + base64.RawURLEncoding.EncodeToString(h.Sum(nil)), nil
+}
+
+// LocalKeyIDFooter returns the `{"kid":"k4.lid..."}` JSON footer identifying
+// the given key by its PASERK local key identifier.
+//
+// The `kid` values of the official test vectors
+// (`zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN`) are opaque sample strings,
+// they are not derived from the keys and can't be reproduced. This footer is
+// the reproducible equivalent used by this library.
+func LocalKeyIDFooter(key *LocalKey) ([]byte, error) {
+	// Compute the key identifier
+	kid, err := LocalKeyID(key)
+	if err != nil {
+		return nil, err
+	}
+
+	// Prepare footer
+	f, err := json.Marshal(&claims.Footer{KeyID: kid})
+	if err != nil {
+		return nil, fmt.Errorf("paseto: unable to encode footer: %w", err)
+	}
+
+	// No error
+	return f, nil
+}
+
+// EncryptWithAutoKID encrypts the message (m) like Encrypt with the
+// LocalKeyIDFooter footer. The footer is bound to the token by the MAC and can
+// be read before decryption to select the key.
+func EncryptWithAutoKID(r io.Reader, key *LocalKey, m, i []byte) (string, error) {
+	// Prepare footer
+	f, err := LocalKeyIDFooter(key)
+	if err != nil {
+		return "", err
+	}
+
+	// No error
+	return Encrypt(r, key, m, f, i)
+}
+
+// PublicFromSecret returns the public key of the given secret key after
+// checking that the public key half embedded in the secret key matches the
+// one derived from its seed, to detect corrupted or tampered secret keys.
+func PublicFromSecret(sk ed25519.PrivateKey) (ed25519.PublicKey, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PrivateKeySize)
+	}
+	if !isSigningKey(sk) {
+		return nil, ErrInconsistentSecretKey
+	}
+
+	// No error
+	return sk.Public().(ed25519.PublicKey), nil
+}
+
+// PublicKeyPASERK serializes the public key as a PASERK `k4.public.` string.
+// https://github.com/paseto-standard/paserk/blob/master/types/public.md
+func PublicKeyPASERK(pk ed25519.PublicKey) (string, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return "", fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PublicKeySize)
+	}
+
+	// No error
+	return paserkPublicPrefix + base64.RawURLEncoding.EncodeToString(pk), nil
+}
+
+// PublicKeyFromPASERK parses a PASERK `k4.public.` string.
+func PublicKeyFromPASERK(s string) (ed25519.PublicKey, error) {
+	// Check header
+	if !strings.HasPrefix(s, paserkPublicPrefix) {
+		return nil, errors.New("paseto: invalid PASERK public key header")
+	}
+
+	// Decode the key
+	pk, err := base64.RawURLEncoding.DecodeString(s[len(paserkPublicPrefix):])
+	if err != nil {
+		return nil, fmt.Errorf("paseto: invalid PASERK public key encoding: %w", err)
+	}
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PublicKeySize)
+	}
+
+	// No error
+	return pk, nil
+}
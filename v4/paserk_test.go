@@ -0,0 +1,127 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/hex"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"zntr.io/paseto/claims"
+)
+
+// https://github.com/paseto-standard/test-vectors/blob/master/PASERK/k4.lid.json
+func Test_LocalKeyID(t *testing.T) {
+	testCases := []struct {
+		name string
+		key  string
+		want string
+	}{
+		{name: "k4.lid-1", key: "0000000000000000000000000000000000000000000000000000000000000000", want: "k4.lid.bqltbNc4JLUAmc9Xtpok-fBuI0dQN5_m3CD9W_nbh559"},
+		{name: "k4.lid-2", key: "707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f", want: "k4.lid.iVtYQDjr5gEijCSjJC3fQaJm7nCeQSeaty0Jixy8dbsk"},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			var key LocalKey
+			_, err := hex.Decode(key[:], []byte(testCase.key))
+			assert.NoError(t, err)
+
+			got, err := LocalKeyID(&key)
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, got)
+		})
+	}
+
+	_, err := LocalKeyID(nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+}
+
+func Test_LocalKeyIDFooter(t *testing.T) {
+	var key LocalKey
+	_, err := hex.Decode(key[:], []byte("707172737475767778797a7b7c7d7e7f808182838485868788898a8b8c8d8e8f"))
+	assert.NoError(t, err)
+
+	f, err := LocalKeyIDFooter(&key)
+	assert.NoError(t, err)
+	assert.Equal(t, `{"kid":"k4.lid.iVtYQDjr5gEijCSjJC3fQaJm7nCeQSeaty0Jixy8dbsk"}`, string(f))
+
+	_, err = LocalKeyIDFooter(nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+}
+
+func Test_Paseto_Local_EncryptWithAutoKID(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	kid, err := LocalKeyID(key)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+	i := []byte("{\"test-vector\":\"auto-kid\"}")
+
+	token, err := EncryptWithAutoKID(rand.Reader, key, m, i)
+	assert.NoError(t, err)
+
+	// The footer identifies the key
+	var footer claims.Footer
+	p, err := DecryptFull(key, token, i, &footer)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+	assert.Equal(t, kid, footer.KeyID)
+
+	_, err = EncryptWithAutoKID(rand.Reader, nil, m, i)
+	assert.ErrorIs(t, err, ErrNilKey)
+}
+
+func Test_PublicFromSecret(t *testing.T) {
+	sk, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	assert.NoError(t, err)
+
+	pk, err := PublicFromSecret(sk)
+	assert.NoError(t, err)
+	assert.Equal(t, "1eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2", hex.EncodeToString(pk))
+
+	p, err := PublicKeyPASERK(pk)
+	assert.NoError(t, err)
+	assert.Equal(t, "k4.public.Hrnbu7wEfAP9cGBOAHHwmH4Wsot1ciXBHwBBXQ4gsaI", p)
+
+	parsed, err := PublicKeyFromPASERK(p)
+	assert.NoError(t, err)
+	assert.Equal(t, pk, parsed)
+
+	// Tampered public key half
+	tampered := append([]byte{}, sk...)
+	tampered[len(tampered)-1] ^= 0x01
+	_, err = PublicFromSecret(tampered)
+	assert.Error(t, err)
+
+	// Invalid lengths
+	_, err = PublicFromSecret(sk[:ed25519.SeedSize])
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+	_, err = PublicKeyPASERK(pk[1:])
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+	_, err = PublicKeyFromPASERK("k4.public.AAAA")
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+	_, err = PublicKeyFromPASERK("k4.local.Hrnbu7wEfAP9cGBOAHHwmH4Wsot1ciXBHwBBXQ4gsaI")
+	assert.Error(t, err)
+	_, err = PublicKeyFromPASERK("k4.public.@@@@")
+	assert.Error(t, err)
+}
@@ -0,0 +1,77 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"errors"
+	"fmt"
+
+	"golang.org/x/crypto/argon2"
+)
+
+// MinSaltLength is the minimum salt size for password based key derivation.
+const MinSaltLength = 16
+
+// Argon2Params holds the Argon2id cost parameters.
+type Argon2Params struct {
+	// Time is the number of passes over the memory.
+	Time uint32
+	// Memory is the memory size in KiB.
+	Memory uint32
+	// Threads is the degree of parallelism.
+	Threads uint8
+}
+
+// DefaultArgon2Params are the PASERK k4 password wrapping defaults
+// (64 MiB, 2 passes, 1 thread).
+var DefaultArgon2Params = Argon2Params{
+	Time:    2,
+	Memory:  64 * 1024,
+	Threads: 1,
+}
+
+// LocalKeyFromPassword derives a local key from a password and a salt using
+// Argon2id.
+//
+// The derivation is deterministic: the same password, salt and parameters
+// always produce the same key. Unlike GenerateLocalKey, the key strength is
+// bounded by the password entropy. The salt must be random, unique per
+// password and stored alongside the encrypted data, as it is required to
+// derive the key again.
+func LocalKeyFromPassword(password, salt []byte, params Argon2Params) (*LocalKey, error) {
+	// Check arguments
+	if len(password) == 0 {
+		return nil, errors.New("paseto: password must not be blank")
+	}
+	if len(salt) < MinSaltLength {
+		return nil, fmt.Errorf("paseto: invalid salt length, it must be %d bytes long at least", MinSaltLength)
+	}
+	if params.Time == 0 || params.Threads == 0 {
+		return nil, errors.New("paseto: argon2 time and threads parameters must be positive")
+	}
+	if params.Memory < 8*uint32(params.Threads) {
+		return nil, fmt.Errorf("paseto: argon2 memory parameter must be %d KiB at least", 8*uint32(params.Threads))
+	}
+
+	// Derive key
+	var key LocalKey
+	copy(key[:], argon2.IDKey(password, salt, params.Time, params.Memory, params.Threads, KeyLength))
+
+	// No error
+	return &key, nil
+}
@@ -0,0 +1,62 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_LocalKeyFromPassword(t *testing.T) {
+	params := Argon2Params{Time: 1, Memory: 64, Threads: 1}
+	password := []byte("correct horse battery staple")
+	salt := []byte("0123456789abcdef")
+
+	key1, err := LocalKeyFromPassword(password, salt, params)
+	assert.NoError(t, err)
+	key2, err := LocalKeyFromPassword(password, salt, params)
+	assert.NoError(t, err)
+	assert.Equal(t, key1, key2)
+
+	// Different salt
+	key3, err := LocalKeyFromPassword(password, []byte("fedcba9876543210"), params)
+	assert.NoError(t, err)
+	assert.NotEqual(t, key1, key3)
+
+	// Different cost
+	key4, err := LocalKeyFromPassword(password, salt, Argon2Params{Time: 2, Memory: 64, Threads: 1})
+	assert.NoError(t, err)
+	assert.NotEqual(t, key1, key4)
+}
+
+func Test_LocalKeyFromPassword_Invalid(t *testing.T) {
+	params := Argon2Params{Time: 1, Memory: 64, Threads: 1}
+
+	_, err := LocalKeyFromPassword(nil, []byte("0123456789abcdef"), params)
+	assert.Error(t, err)
+
+	_, err = LocalKeyFromPassword([]byte("password"), []byte("short"), params)
+	assert.Error(t, err)
+
+	_, err = LocalKeyFromPassword([]byte("password"), []byte("0123456789abcdef"), Argon2Params{})
+	assert.Error(t, err)
+
+	_, err = LocalKeyFromPassword([]byte("password"), []byte("0123456789abcdef"), Argon2Params{Time: 1, Memory: 4, Threads: 1})
+	assert.Error(t, err)
+}
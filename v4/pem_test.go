@@ -0,0 +1,119 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/pem"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_PublicKeysFromPEMBundle(t *testing.T) {
+	pk1, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pk2, sk2, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	ec, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	encode := func(typ string, key any) []byte {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		assert.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: typ, Bytes: der})
+	}
+
+	var bundle []byte
+	bundle = append(bundle, "# current\n"...)
+	bundle = append(bundle, encode("PUBLIC KEY", pk1)...)
+	bundle = append(bundle, encode("CERTIFICATE", pk1)...)
+	bundle = append(bundle, "# previous\n"...)
+	bundle = append(bundle, encode("PUBLIC KEY", pk2)...)
+
+	keys, err := PublicKeysFromPEMBundle(bundle)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+	assert.True(t, PublicKeysEqual(pk1, keys[0]))
+	assert.True(t, PublicKeysEqual(pk2, keys[1]))
+
+	// Feeds the multi-key verifier
+	token, err := Sign([]byte("{}"), sk2, nil, nil)
+	assert.NoError(t, err)
+	_, idx, err := VerifyAny(token, keys, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, idx)
+
+	// Invalid bundles
+	_, err = PublicKeysFromPEMBundle(nil)
+	assert.Error(t, err)
+	_, err = PublicKeysFromPEMBundle(append(bundle, encode("PUBLIC KEY", &ec.PublicKey)...))
+	assert.ErrorContains(t, err, "block 3")
+	_, err = PublicKeysFromPEMBundle(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: []byte("garbage")}))
+	assert.Error(t, err)
+}
+
+func Test_Paseto_SignVerifyPEM(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	ec, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	assert.NoError(t, err)
+
+	encodePrivate := func(key any) []byte {
+		der, err := x509.MarshalPKCS8PrivateKey(key)
+		assert.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})
+	}
+	encodePublic := func(key any) []byte {
+		der, err := x509.MarshalPKIXPublicKey(key)
+		assert.NoError(t, err)
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := []byte("{\"kid\":\"1234\"}")
+	i := []byte("{\"user_id\":\"1234\"}")
+
+	token, err := SignPEM(m, encodePrivate(sk), f, i)
+	assert.NoError(t, err)
+
+	// Same token as Sign
+	expected, err := Sign(m, sk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, token)
+
+	p, err := VerifyPEM(token, encodePublic(pk), f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Invalid keys
+	_, err = SignPEM(m, encodePrivate(ec), f, i)
+	assert.Error(t, err)
+	_, err = SignPEM(m, encodePublic(pk), f, i)
+	assert.Error(t, err)
+	_, err = SignPEM(m, []byte("garbage"), f, i)
+	assert.Error(t, err)
+	_, err = VerifyPEM(token, encodePublic(&ec.PublicKey), f, i)
+	assert.Error(t, err)
+	_, err = VerifyPEM(token, encodePrivate(sk), f, i)
+	assert.Error(t, err)
+}
@@ -0,0 +1,57 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/sha512"
+	"fmt"
+
+	"zntr.io/paseto/internal/common"
+)
+
+// VerifyPrehash verifies a token signed with Ed25519ph (SHA-512 prehashed
+// pre-authentication encoding) instead of pure Ed25519.
+//
+// NON STANDARD: PASETO v4 mandates pure Ed25519, the accepted tokens are not
+// spec compliant. This is a migration shim for legacy producers only, it must
+// be explicitly opted in and never used to verify standard tokens.
+func VerifyPrehash(t string, pk ed25519.PublicKey, f, i []byte) ([]byte, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PublicKeySize)
+	}
+
+	// Decode token
+	m, s, err := decodePublicToken(t, f)
+	if err != nil {
+		return nil, err
+	}
+
+	// Compute protected content digest
+	digest := sha512.Sum512(common.PreAuthenticationEncoding([]byte(PublicPrefix), m, f, i))
+
+	// Check signature
+	if err := ed25519.VerifyWithOptions(pk, digest[:], s, &ed25519.Options{Hash: crypto.SHA512}); err != nil {
+		return nil, fmt.Errorf("%w: %v", ErrInvalidSignature, err)
+	}
+
+	// No error
+	return m, nil
+}
@@ -0,0 +1,64 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/sha512"
+	"encoding/base64"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"zntr.io/paseto/internal/common"
+)
+
+func Test_Paseto_Public_VerifyPrehash(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\",\"exp\":\"2022-01-01T00:00:00+00:00\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"4-S-3\"}")
+
+	// Sign with Ed25519ph like the legacy producer
+	digest := sha512.Sum512(common.PreAuthenticationEncoding([]byte(PublicPrefix), m, f, i))
+	sig, err := sk.Sign(nil, digest[:], &ed25519.Options{Hash: crypto.SHA512})
+	assert.NoError(t, err)
+	token := PublicPrefix + base64.RawURLEncoding.EncodeToString(append(append([]byte{}, m...), sig...)) + "." + base64.RawURLEncoding.EncodeToString(f)
+
+	p, err := VerifyPrehash(token, pk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	// Prehashed signatures are rejected by the standard verification
+	_, err = Verify(token, pk, f, i)
+	assert.Error(t, err)
+
+	// Standard signatures are rejected by the prehash verification
+	token, err = Sign(m, sk, f, i)
+	assert.NoError(t, err)
+	_, err = VerifyPrehash(token, pk, f, i)
+	assert.Error(t, err)
+
+	// Invalid key
+	_, err = VerifyPrehash(token, pk[1:], f, i)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+}
@@ -18,12 +18,13 @@
 package v4
 
 import (
-	"bytes"
 	"crypto/ed25519"
 	"crypto/subtle"
-	"encoding/base64"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
+	"strings"
 
 	"zntr.io/paseto/internal/common"
 )
@@ -32,93 +33,346 @@ import (
 // PASETO v4 public signature primitive.
 // https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#sign
 func Sign(m []byte, sk ed25519.PrivateKey, f, i []byte) (string, error) {
-	// Compute protected content
-	m2 := common.PreAuthenticationEncoding([]byte(PublicPrefix), m, f, i)
+	// Sign into a new buffer
+	token, err := AppendSign(nil, m, sk, f, i)
+	if err != nil {
+		return "", err
+	}
+
+	// No error
+	return string(token), nil
+}
 
+// AppendSign signs the message (m) like Sign and appends the token to dst,
+// returning the extended buffer. dst is grown at most once, use SignedLen to
+// size it beforehand.
+func AppendSign(dst, m []byte, sk ed25519.PrivateKey, f, i []byte) ([]byte, error) {
 	// Sign protected content
-	sig := ed25519.Sign(sk, m2)
+	sig, err := SignDetached(m, sk, f, i)
+	if err != nil {
+		return nil, err
+	}
 
 	// Prepare content
 	body := make([]byte, 0, len(m)+ed25519.SignatureSize)
 	body = append(body, m...)
 	body = append(body, sig...)
 
-	// Encode body as RawURLBase64
-	tokenLen := base64.RawURLEncoding.EncodedLen(len(body))
-	footerLen := base64.RawURLEncoding.EncodedLen(len(f)) + 1
-	if len(f) > 0 {
-		tokenLen += base64.RawURLEncoding.EncodedLen(len(f)) + 1
+	// No error
+	return appendToken(dst, PublicPrefix, body, f), nil
+}
+
+// SignTo signs the message (m) like Sign and writes the token to w, skipping
+// the string conversion. It returns the number of bytes written.
+func SignTo(w io.Writer, m []byte, sk ed25519.PrivateKey, f, i []byte) (int, error) {
+	// Sign into a buffer sized for the token
+	token, err := AppendSign(make([]byte, 0, SignedLen(len(m), len(f))), m, sk, f, i)
+	if err != nil {
+		return 0, err
 	}
 
-	final := make([]byte, tokenLen+len(PublicPrefix))
-	copy(final, PublicPrefix)
-	base64.RawURLEncoding.Encode(final[10:], body)
+	// No error
+	return w.Write(token)
+}
 
-	// Assemble final token
-	if len(f) > 0 {
-		final[10+tokenLen-footerLen] = '.'
-		// Encode footer as RawURLBase64
-		base64.RawURLEncoding.Encode(final[10+tokenLen-footerLen+1:], f)
+// SignedLen returns the exact length of the token produced by Sign for a
+// message of msgLen bytes and a footer of footerLen bytes.
+func SignedLen(msgLen, footerLen int) int {
+	return tokenLen(len(PublicPrefix), msgLen+ed25519.SignatureSize, footerLen)
+}
+
+// SignDetached computes the PASETO v4 public signature of the message (m) with
+// the private key (sk) and returns the raw signature without assembling the
+// token.
+//
+// The signature is computed over the same pre-authentication encoding as Sign,
+// so a token can be reassembled later from the message, the signature and the
+// footer.
+func SignDetached(m []byte, sk ed25519.PrivateKey, f, i []byte) ([]byte, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PrivateKeySize)
 	}
 
+	// Compute protected content
+	m2 := common.PreAuthenticationEncoding([]byte(PublicPrefix), m, f, i)
+
 	// No error
-	return string(final), nil
+	return ed25519.Sign(sk, m2), nil
 }
 
 // PASETO v4 signature verification primitive.
 // https://github.com/paseto-standard/paseto-spec/blob/master/docs/01-Protocol-Versions/Version4.md#verify
 func Verify(t string, pk ed25519.PublicKey, f, i []byte) ([]byte, error) {
-	rawToken := []byte(t)
+	// Decode token
+	m, s, err := decodePublicToken(t, f)
+	if err != nil {
+		return nil, err
+	}
 
-	// Check token header
-	if !bytes.HasPrefix(rawToken, []byte(PublicPrefix)) {
-		return nil, errors.New("paseto: invalid token")
+	// Check signature
+	if err := VerifyDetached(m, s, pk, f, i); err != nil {
+		return nil, err
 	}
 
-	// Trim prefix
-	rawToken = rawToken[len(PublicPrefix):]
+	// No error
+	return m, nil
+}
 
-	// Check footer usage
-	if len(f) > 0 {
-		// Split the footer and the body
-		footerIdx := bytes.Index(rawToken, []byte("."))
-		if footerIdx == 0 {
-			return nil, errors.New("paseto: invalid token, footer is missing but expected")
-		}
+// VerifyDetached verifies a raw PASETO v4 public signature (sig) of the message
+// (m) produced by SignDetached or extracted from a token.
+func VerifyDetached(m, sig []byte, pk ed25519.PublicKey, f, i []byte) error {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PublicKeySize)
+	}
+	if len(sig) != ed25519.SignatureSize {
+		return errors.New("paseto: invalid signature length")
+	}
 
-		// Decode footer
-		footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawToken[footerIdx+1:])))
-		if _, err := base64.RawURLEncoding.Decode(footer, rawToken[footerIdx+1:]); err != nil {
-			return nil, fmt.Errorf("paseto: invalid token, footer has invalid encoding: %w", err)
-		}
+	// Compute protected content
+	m2 := common.PreAuthenticationEncoding([]byte(PublicPrefix), m, f, i)
 
-		// Compare footer
-		if subtle.ConstantTimeCompare(f, footer) == 0 {
-			return nil, errors.New("paseto: invalid token, footer mismatch")
-		}
+	// Check signature
+	if !ed25519.Verify(pk, m2, sig) {
+		return common.AuthError(ErrInvalidSignature, i)
+	}
 
-		// Continue without footer
-		rawToken = rawToken[:footerIdx]
+	// No error
+	return nil
+}
+
+// SignWithPAE signs the message (m) like Sign and also returns the
+// pre-authentication encoding which has been signed, so it can be logged as an
+// audit artifact.
+func SignWithPAE(m []byte, sk ed25519.PrivateKey, f, i []byte) (string, []byte, error) {
+	// Sign the token
+	token, err := Sign(m, sk, f, i)
+	if err != nil {
+		return "", nil, err
 	}
 
+	// No error
+	return token, common.PreAuthenticationEncoding([]byte(PublicPrefix), m, f, i), nil
+}
+
+// VerifyWithPAE verifies the token like Verify and also returns the
+// pre-authentication encoding which has been verified, so it can be logged as
+// an audit artifact.
+func VerifyWithPAE(t string, pk ed25519.PublicKey, f, i []byte) (m, pae []byte, err error) {
 	// Decode token
-	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawToken)))
-	if _, err := base64.RawURLEncoding.Decode(raw, rawToken); err != nil {
-		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
+	m, s, err := decodePublicToken(t, f)
+	if err != nil {
+		return nil, nil, err
 	}
 
-	// Extract components
+	// Check signature
+	if err := VerifyDetached(m, s, pk, f, i); err != nil {
+		return nil, nil, err
+	}
+
+	// No error
+	return m, common.PreAuthenticationEncoding([]byte(PublicPrefix), m, f, i), nil
+}
+
+// ErrUntrustedKey is raised when the trust policy rejects the public key
+// declared by a self-described token.
+var ErrUntrustedKey = errors.New("paseto: untrusted token public key")
+
+// VerifySelfDescribed verifies a token signed by the public key declared in
+// its footer (`{"pk":"k4.public..."}`) and returns the message and the key.
+//
+// DANGER: anyone can sign a token and declare its own key, so the signature
+// alone proves nothing. The trust callback is the only authentication
+// decision: it must check the key against a pinning or trust-on-first-use
+// policy, and must never accept any key. It is called before the signature is
+// checked.
+func VerifySelfDescribed(t string, trust func(ed25519.PublicKey) bool, i []byte) ([]byte, ed25519.PublicKey, error) {
+	// Check arguments
+	if trust == nil {
+		return nil, nil, errors.New("paseto: trust policy is required")
+	}
+
+	// Decode token
+	raw, footer, err := decodeToken(PublicPrefix, t)
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(raw) < ed25519.SignatureSize {
+		return nil, nil, fmt.Errorf("%w body, signature is missing", ErrInvalidToken)
+	}
+
+	// Extract the declared key
+	var f struct {
+		PublicKey string `json:"pk"`
+	}
+	if len(footer) == 0 {
+		return nil, nil, ErrMissingFooter
+	}
+	if err := json.Unmarshal(footer, &f); err != nil {
+		return nil, nil, fmt.Errorf("%w, footer is not a JSON object: %v", ErrInvalidToken, err)
+	}
+	pk, err := PublicKeyFromPASERK(f.PublicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("%w, footer public key: %v", ErrInvalidToken, err)
+	}
+
+	// Apply the trust policy
+	if !trust(pk) {
+		return nil, nil, ErrUntrustedKey
+	}
+
+	// Check signature
 	m := raw[:len(raw)-ed25519.SignatureSize]
-	s := raw[len(raw)-ed25519.SignatureSize:]
+	if err := VerifyDetached(m, raw[len(raw)-ed25519.SignatureSize:], pk, footer, i); err != nil {
+		return nil, nil, err
+	}
+
+	// No error
+	return m, pk, nil
+}
+
+// VerifyAny verifies the token signature against a set of candidate public
+// keys and returns the message and the index of the first key which verified
+// the signature.
+//
+// All keys are always tried so that the time spent doesn't depend on the
+// position of the matching key, and a single error is returned when no key
+// matches to avoid disclosing which key failed.
+func VerifyAny(t string, pks []ed25519.PublicKey, f, i []byte) ([]byte, int, error) {
+	// Check arguments
+	if len(pks) == 0 {
+		return nil, -1, errors.New("paseto: at least one public key is required")
+	}
+
+	// Decode token
+	m, s, err := decodePublicToken(t, f)
+	if err != nil {
+		return nil, -1, err
+	}
 
 	// Compute protected content
 	m2 := common.PreAuthenticationEncoding([]byte(PublicPrefix), m, f, i)
 
-	// Check signature
-	if !ed25519.Verify(pk, m2, s) {
-		return nil, errors.New("paseto: invalid token signature")
+	// Check signature with all keys
+	idx := -1
+	for j, pk := range pks {
+		valid := 0
+		if len(pk) == ed25519.PublicKeySize && ed25519.Verify(pk, m2, s) {
+			valid = 1
+		}
+
+		// Keep the first matching key index
+		first := subtle.ConstantTimeEq(int32(idx), -1) & valid
+		idx = subtle.ConstantTimeSelect(first, j, idx)
+	}
+	if idx < 0 {
+		return nil, -1, common.AuthError(ErrInvalidSignature, i)
 	}
 
 	// No error
-	return m, nil
+	return m, idx, nil
+}
+
+// ExtractSignature splits a PASETO v4 public token into its message,
+// signature and footer without verifying it. The token structure is validated
+// but no cryptographic operation is done, so the components are not
+// authenticated.
+//
+// It is meant to help interoperability debugging, use Verify to trust the
+// message.
+func ExtractSignature(t string) (m, sig, footer []byte, err error) {
+	// Decode token
+	raw, footer, err := decodeToken(PublicPrefix, t)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	// Check body length
+	if len(raw) < ed25519.SignatureSize {
+		return nil, nil, nil, fmt.Errorf("%w body, signature is missing", ErrInvalidToken)
+	}
+
+	// No error
+	split := len(raw) - ed25519.SignatureSize
+	return raw[:split:split], raw[split:], footer, nil
+}
+
+// IsPublic returns true when the token has the `v4.public.` header. It only checks
+// the header, the token is neither decoded nor authenticated.
+func IsPublic(token string) bool {
+	return strings.HasPrefix(token, PublicPrefix)
+}
+
+// PublicKeysEqual reports whether a and b are the same public key. It is nil
+// safe and relies on ed25519.PublicKey.Equal which compares in constant time.
+func PublicKeysEqual(a, b ed25519.PublicKey) bool {
+	if a == nil || b == nil {
+		return a == nil && b == nil
+	}
+
+	return a.Equal(b)
+}
+
+// KeyPairMatches reports whether the secret key (sk) is consistent and its
+// public half is pk, compared in constant time. It is intended as a startup
+// self-check to detect a configuration mismatch.
+func KeyPairMatches(sk ed25519.PrivateKey, pk ed25519.PublicKey) bool {
+	// Derive the public key from the seed
+	derived, err := PublicFromSecret(sk)
+	if err != nil {
+		return false
+	}
+
+	return len(pk) == ed25519.PublicKeySize && PublicKeysEqual(derived, pk)
+}
+
+// PrivateKeyFromBytes creates a private key from either a 32-byte seed, which
+// is expanded, or a 64-byte full private key (seed || public key). The public
+// half of a full private key must match the one derived from its seed,
+// ErrInconsistentSecretKey is raised otherwise.
+func PrivateKeyFromBytes(b []byte) (ed25519.PrivateKey, error) {
+	switch len(b) {
+	case ed25519.SeedSize:
+		return ed25519.NewKeyFromSeed(b), nil
+	case ed25519.PrivateKeySize:
+		if !isSigningKey(b) {
+			return nil, ErrInconsistentSecretKey
+		}
+
+		// Copy to detach the key from the caller buffer
+		sk := make(ed25519.PrivateKey, ed25519.PrivateKeySize)
+		copy(sk, b)
+
+		return sk, nil
+	}
+
+	return nil, fmt.Errorf("%w, it must be %d or %d bytes long", ErrInvalidKeyLength, ed25519.SeedSize, ed25519.PrivateKeySize)
+}
+
+// -----------------------------------------------------------------------------
+
+func decodePublicToken(t string, f []byte) (m, s []byte, err error) {
+	// Decode token
+	raw, footer, err := decodeToken(PublicPrefix, t)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	// Check footer usage
+	if err := checkFooter(f, footer); err != nil {
+		return nil, nil, err
+	}
+
+	// Check body length
+	if len(raw) < ed25519.SignatureSize {
+		return nil, nil, fmt.Errorf("%w body, signature is missing", ErrInvalidToken)
+	}
+
+	// Extract components
+	m = raw[:len(raw)-ed25519.SignatureSize]
+	s = raw[len(raw)-ed25519.SignatureSize:]
+
+	// No error
+	return m, s, nil
 }
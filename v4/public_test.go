@@ -18,8 +18,12 @@
 package v4
 
 import (
+	"bytes"
 	"crypto/ed25519"
+	"crypto/rand"
+	"encoding/base64"
 	"encoding/hex"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -118,6 +122,426 @@ func Test_Paseto_PublicVector(t *testing.T) {
 	}
 }
 
+func Test_Paseto_Public_VerifyAny(t *testing.T) {
+	pk1, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pk2, sk2, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pk3, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\",\"exp\":\"2022-01-01T00:00:00+00:00\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"4-S-3\"}")
+
+	token, err := Sign(m, sk2, f, i)
+	assert.NoError(t, err)
+
+	// Matching key
+	message, idx, err := VerifyAny(token, []ed25519.PublicKey{pk1, pk2, pk3}, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, idx)
+	assert.Equal(t, m, message)
+
+	// Duplicate keys return the first index
+	_, idx, err = VerifyAny(token, []ed25519.PublicKey{pk1, pk2, pk2}, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, idx)
+
+	// Invalid key lengths are ignored
+	_, idx, err = VerifyAny(token, []ed25519.PublicKey{nil, pk2}, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, idx)
+
+	// No matching key
+	_, idx, err = VerifyAny(token, []ed25519.PublicKey{pk1, pk3}, f, i)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	assert.ErrorIs(t, err, ErrPossibleImplicitMismatch)
+	assert.Equal(t, -1, idx)
+
+	// No matching key without implicit assertion
+	_, _, err = VerifyAny(token, []ed25519.PublicKey{pk1, pk3}, f, nil)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	assert.NotErrorIs(t, err, ErrPossibleImplicitMismatch)
+
+	// No key
+	_, _, err = VerifyAny(token, nil, f, i)
+	assert.Error(t, err)
+}
+
+func Test_Paseto_Public_EmptyMessage(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"heartbeat\"}")
+
+	for _, m := range [][]byte{nil, {}} {
+		for _, footer := range [][]byte{nil, f} {
+			token, err := Sign(m, sk, footer, i)
+			assert.NoError(t, err)
+
+			p, err := Verify(token, pk, footer, i)
+			assert.NoError(t, err)
+			assert.Empty(t, p)
+		}
+	}
+}
+
+func Test_Paseto_Public_Detached(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\",\"exp\":\"2022-01-01T00:00:00+00:00\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"4-S-3\"}")
+
+	sig, err := SignDetached(m, sk, f, i)
+	assert.NoError(t, err)
+	assert.Len(t, sig, ed25519.SignatureSize)
+	assert.NoError(t, VerifyDetached(m, sig, pk, f, i))
+
+	// Same signature as the assembled token
+	token, err := Sign(m, sk, f, i)
+	assert.NoError(t, err)
+	body, err := base64.RawURLEncoding.DecodeString(strings.Split(strings.TrimPrefix(token, PublicPrefix), ".")[0])
+	assert.NoError(t, err)
+	assert.Equal(t, sig, body[len(m):])
+
+	// Footer and implicit assertion are bound to the signature
+	assert.Error(t, VerifyDetached(m, sig, pk, nil, i))
+	assert.Error(t, VerifyDetached(m, sig, pk, f, nil))
+	assert.Error(t, VerifyDetached(m[1:], sig, pk, f, i))
+
+	// Invalid arguments
+	_, err = SignDetached(m, sk[:ed25519.SeedSize], f, i)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+	assert.ErrorIs(t, VerifyDetached(m, sig, pk[1:], f, i), ErrInvalidKeyLength)
+	assert.Error(t, VerifyDetached(m, sig[1:], pk, f, i))
+}
+
+func Test_Paseto_Public_SignedLen(t *testing.T) {
+	_, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	for msgLen := 0; msgLen < 8; msgLen++ {
+		for _, footerLen := range []int{0, 1, 2, 3, 4, 5} {
+			token, err := Sign(make([]byte, msgLen), sk, []byte(strings.Repeat("f", footerLen)), nil)
+			assert.NoError(t, err)
+			assert.Equal(t, len(token), SignedLen(msgLen, footerLen), "msgLen=%d footerLen=%d", msgLen, footerLen)
+		}
+	}
+}
+
+func Test_Paseto_Public_Tampered(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	otherPk, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"tampered\"}")
+
+	token, err := Sign(m, sk, f, i)
+	assert.NoError(t, err)
+
+	// v.public.body.footer
+	parts := strings.Split(token, ".")
+	body, err := base64.RawURLEncoding.DecodeString(parts[2])
+	assert.NoError(t, err)
+
+	flip := func(offset int) string {
+		raw := append([]byte{}, body...)
+		raw[offset] ^= 0x01
+		return parts[0] + "." + parts[1] + "." + base64.RawURLEncoding.EncodeToString(raw) + "." + parts[3]
+	}
+	alteredFooter := []byte("{\"kid\":\"attacker\"}")
+	alteredToken := parts[0] + "." + parts[1] + "." + parts[2] + "." + base64.RawURLEncoding.EncodeToString(alteredFooter)
+
+	testCases := []struct {
+		name    string
+		pk      ed25519.PublicKey
+		token   string
+		footer  []byte
+		ia      []byte
+		wantErr error
+	}{
+		{name: "message bit flip", pk: pk, token: flip(0), footer: f, ia: i, wantErr: ErrInvalidSignature},
+		{name: "signature bit flip", pk: pk, token: flip(len(body) - 1), footer: f, ia: i, wantErr: ErrInvalidSignature},
+		{name: "altered footer", pk: pk, token: alteredToken, footer: alteredFooter, ia: i, wantErr: ErrInvalidSignature},
+		{name: "footer mismatch", pk: pk, token: alteredToken, footer: f, ia: i, wantErr: ErrInvalidToken},
+		{name: "wrong implicit assertion", pk: pk, token: token, footer: f, ia: []byte("{\"test-vector\":\"other\"}"), wantErr: ErrInvalidSignature},
+		{name: "missing implicit assertion", pk: pk, token: token, footer: f, ia: nil, wantErr: ErrInvalidSignature},
+		{name: "wrong key", pk: otherPk, token: token, footer: f, ia: i, wantErr: ErrInvalidSignature},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Verify(testCase.token, testCase.pk, testCase.footer, testCase.ia)
+			assert.ErrorIs(t, err, testCase.wantErr)
+		})
+	}
+
+	// Untampered token
+	p, err := Verify(token, pk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+}
+
+func Test_PublicKeysEqual(t *testing.T) {
+	pk1, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pk2, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	assert.True(t, PublicKeysEqual(pk1, append(ed25519.PublicKey{}, pk1...)))
+	assert.False(t, PublicKeysEqual(pk1, pk2))
+	assert.False(t, PublicKeysEqual(pk1, nil))
+	assert.True(t, PublicKeysEqual(nil, nil))
+}
+
+func Test_Paseto_Public_AppendSign(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+
+	// Reuse a scratch buffer
+	buf := make([]byte, 0, SignedLen(len(m), len(f)))
+	for j := 0; j < 2; j++ {
+		out, err := AppendSign(buf[:0], m, sk, f, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, cap(buf), cap(out), "buffer must not be reallocated")
+
+		token, err := Sign(m, sk, f, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, token, string(out))
+
+		p, err := Verify(string(out), pk, f, nil)
+		assert.NoError(t, err)
+		assert.Equal(t, m, p)
+	}
+}
+
+func Test_Paseto_Public_WithPAE(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := []byte("{\"kid\":\"1234567890\"}")
+	i := []byte("{\"test-vector\":\"pae\"}")
+
+	token, signedPAE, err := SignWithPAE(m, sk, f, i)
+	assert.NoError(t, err)
+
+	got, verifiedPAE, err := VerifyWithPAE(token, pk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, got)
+	assert.Equal(t, signedPAE, verifiedPAE)
+
+	// The signature covers the returned PAE
+	_, sig, err := decodePublicToken(token, f)
+	assert.NoError(t, err)
+	assert.True(t, ed25519.Verify(pk, verifiedPAE, sig))
+
+	// No PAE is returned on failure
+	_, pae, err := VerifyWithPAE(token, pk, f, nil)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	assert.Nil(t, pae)
+
+	_, _, err = SignWithPAE(m, sk[:32], f, i)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+}
+
+func Test_Paseto_Public_SignTo(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+
+	var buf strings.Builder
+	n, err := SignTo(&buf, m, sk, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, SignedLen(len(m), len(f)), n)
+
+	token, err := Sign(m, sk, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, token, buf.String())
+
+	p, err := Verify(buf.String(), pk, f, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, m, p)
+
+	_, err = SignTo(&buf, m, sk[:32], f, nil)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+}
+
+func Test_KeyPairMatches(t *testing.T) {
+	pk1, sk1, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pk2, sk2, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	assert.True(t, KeyPairMatches(sk1, pk1))
+	assert.False(t, KeyPairMatches(sk1, pk2))
+	assert.False(t, KeyPairMatches(sk1, nil))
+	assert.False(t, KeyPairMatches(nil, pk1))
+	assert.False(t, KeyPairMatches(sk1[:32], pk1))
+
+	// Inconsistent secret key (public half swapped)
+	tampered := make(ed25519.PrivateKey, ed25519.PrivateKeySize)
+	copy(tampered, sk1[:32])
+	copy(tampered[32:], sk2[32:])
+	assert.False(t, KeyPairMatches(tampered, pk2))
+}
+
+func Test_PrivateKeyFromBytes(t *testing.T) {
+	seed := bytes.Repeat([]byte{0x42}, ed25519.SeedSize)
+	expected := ed25519.NewKeyFromSeed(seed)
+
+	corrupted := bytes.Clone(expected)
+	corrupted[ed25519.PrivateKeySize-1] ^= 0x01
+
+	testCases := []struct {
+		name    string
+		input   []byte
+		wantErr bool
+		errIs   error
+	}{
+		{name: "seed", input: seed},
+		{name: "full key", input: expected},
+		{name: "corrupted public half", input: corrupted, wantErr: true, errIs: ErrInconsistentSecretKey},
+		{name: "nil", input: nil, wantErr: true, errIs: ErrInvalidKeyLength},
+		{name: "public key size", input: seed[:ed25519.PublicKeySize-1], wantErr: true, errIs: ErrInvalidKeyLength},
+		{name: "too long", input: append(bytes.Clone(expected), 0x00), wantErr: true, errIs: ErrInvalidKeyLength},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			sk, err := PrivateKeyFromBytes(testCase.input)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, testCase.errIs)
+				assert.Nil(t, sk)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, expected, sk)
+		})
+	}
+
+	// The full key is detached from the input buffer
+	input := bytes.Clone(expected)
+	sk, err := PrivateKeyFromBytes(input)
+	assert.NoError(t, err)
+	input[0] ^= 0x01
+	assert.Equal(t, expected, sk)
+}
+
+func Test_PrivateKeyFromBytes_Vector(t *testing.T) {
+	// Keys from the 4-S-* test vectors
+	seed, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a3774")
+	assert.NoError(t, err)
+	secretKey, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	assert.NoError(t, err)
+
+	fromSeed, err := PrivateKeyFromBytes(seed)
+	assert.NoError(t, err)
+	assert.Equal(t, secretKey, []byte(fromSeed))
+
+	fromFull, err := PrivateKeyFromBytes(secretKey)
+	assert.NoError(t, err)
+	assert.Equal(t, fromSeed, fromFull)
+}
+
+func Test_Paseto_Public_VerifySelfDescribed(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	pinned, err := PublicKeyPASERK(pk)
+	assert.NoError(t, err)
+	trust := func(candidate ed25519.PublicKey) bool { return PublicKeysEqual(candidate, pk) }
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := []byte(`{"pk":"` + pinned + `"}`)
+	i := []byte("{\"test-vector\":\"self-described\"}")
+
+	token, err := Sign(m, sk, f, i)
+	assert.NoError(t, err)
+
+	got, gotPK, err := VerifySelfDescribed(token, trust, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, got)
+	assert.Equal(t, pk, gotPK)
+
+	// Untrusted key, even with a valid signature
+	_, _, err = VerifySelfDescribed(token, func(ed25519.PublicKey) bool { return false }, i)
+	assert.ErrorIs(t, err, ErrUntrustedKey)
+
+	// Declared key doesn't match the signing key
+	_, sk2, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	forged, err := Sign(m, sk2, f, i)
+	assert.NoError(t, err)
+	_, _, err = VerifySelfDescribed(forged, trust, i)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Implicit assertion mismatch
+	_, _, err = VerifySelfDescribed(token, trust, nil)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+
+	// Invalid footers
+	for _, footer := range []string{"", "pk", `{"kid":"1234"}`, `{"pk":"k4.public.AAAA"}`} {
+		token, err := Sign(m, sk, []byte(footer), i)
+		assert.NoError(t, err)
+		_, _, err = VerifySelfDescribed(token, trust, i)
+		assert.ErrorIs(t, err, ErrInvalidToken)
+	}
+
+	// Trust policy is mandatory
+	_, _, err = VerifySelfDescribed(token, nil, i)
+	assert.Error(t, err)
+}
+
+func Test_Paseto_Public_ExtractSignature(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	m := []byte("{\"data\":\"this is a signed message\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"extract\"}")
+
+	token, err := Sign(m, sk, f, i)
+	assert.NoError(t, err)
+
+	gotM, sig, footer, err := ExtractSignature(token)
+	assert.NoError(t, err)
+	assert.Equal(t, m, gotM)
+	assert.Equal(t, f, footer)
+	assert.Len(t, sig, ed25519.SignatureSize)
+
+	detached, err := SignDetached(m, sk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, detached, sig)
+	assert.NoError(t, VerifyDetached(gotM, sig, pk, footer, i))
+
+	// Empty message
+	token, err = Sign(nil, sk, nil, nil)
+	assert.NoError(t, err)
+	gotM, sig, footer, err = ExtractSignature(token)
+	assert.NoError(t, err)
+	assert.Empty(t, gotM)
+	assert.Empty(t, footer)
+	assert.Len(t, sig, ed25519.SignatureSize)
+
+	// Invalid tokens
+	_, _, _, err = ExtractSignature(PublicPrefix + "AAAA")
+	assert.ErrorIs(t, err, ErrInvalidToken)
+	_, _, _, err = ExtractSignature("v4.local." + strings.TrimPrefix(token, PublicPrefix))
+	assert.ErrorIs(t, err, ErrWrongPurpose)
+}
+
 // -----------------------------------------------------------------------------
 
 func benchmarkSign(m []byte, sk ed25519.PrivateKey, f, i []byte, b *testing.B) {
@@ -0,0 +1,139 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+)
+
+// StoredToken describes a persisted PASETO v4 local token.
+type StoredToken struct {
+	// ID is an opaque identifier used to correlate the input and output tokens.
+	ID string
+	// Token is the PASETO v4 local token.
+	Token string
+	// Implicit is the implicit assertion bound to the token.
+	Implicit []byte
+}
+
+// Rewrap decrypts the tokens received from in with the old key and encrypts
+// them again with the new key using concurrency workers. The footer and the
+// implicit assertion of each token are preserved. The nonces are read from r,
+// which is shared by the workers and must be safe for concurrent use (like
+// crypto/rand.Reader).
+//
+// Rewrapped tokens are sent to out in no particular order, use the ID to
+// correlate them. The first error cancels the remaining work and is returned.
+// Rewrap returns when in is closed and all received tokens are processed; out
+// is not closed. On error, the remaining tokens of in are discarded in the
+// background so that the producer is not blocked, in must still be closed to
+// release it.
+func Rewrap(ctx context.Context, r io.Reader, oldKey, newKey *LocalKey, in <-chan StoredToken, out chan<- StoredToken, concurrency int) error {
+	// Check arguments
+	if r == nil {
+		return errors.New("paseto: random source is required")
+	}
+	if oldKey == nil || newKey == nil {
+		return ErrNilKey
+	}
+	if in == nil || out == nil {
+		return errors.New("paseto: input and output channels are required")
+	}
+	if concurrency < 1 {
+		concurrency = 1
+	}
+
+	ctx, cancel := context.WithCancelCause(ctx)
+	defer cancel(nil)
+
+	// Start workers
+	var wg sync.WaitGroup
+	for range concurrency {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				select {
+				case <-ctx.Done():
+					return
+				case st, ok := <-in:
+					if !ok {
+						return
+					}
+
+					rewrapped, err := rewrapToken(r, oldKey, newKey, st)
+					if err != nil {
+						cancel(err)
+						return
+					}
+
+					select {
+					case <-ctx.Done():
+						return
+					case out <- rewrapped:
+					}
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Return the first error (or the parent context error)
+	if err := context.Cause(ctx); err != nil {
+		// Unblock the producer until it closes the input channel
+		go func() {
+			for range in {
+			}
+		}()
+		return err
+	}
+
+	// No error
+	return nil
+}
+
+// -----------------------------------------------------------------------------
+
+func rewrapToken(r io.Reader, oldKey, newKey *LocalKey, st StoredToken) (StoredToken, error) {
+	// Decode token
+	raw, footer, err := decodeToken(LocalPrefix, st.Token)
+	if err != nil {
+		return st, fmt.Errorf("paseto: unable to rewrap token %q: %w", st.ID, err)
+	}
+
+	// Decrypt with the old key
+	m, err := decryptBody(oldKey, raw, footer, st.Implicit)
+	if err != nil {
+		return st, fmt.Errorf("paseto: unable to rewrap token %q: %w", st.ID, err)
+	}
+	defer clear(m)
+
+	// Encrypt with the new key
+	token, err := Encrypt(r, newKey, m, footer, st.Implicit)
+	if err != nil {
+		return st, fmt.Errorf("paseto: unable to rewrap token %q: %w", st.ID, err)
+	}
+
+	// No error
+	st.Token = token
+	return st, nil
+}
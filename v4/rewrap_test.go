@@ -0,0 +1,160 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	"testing"
+	"testing/iotest"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_Rewrap(t *testing.T) {
+	oldKey, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	newKey, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Prepare tokens
+	tokens := map[string]StoredToken{}
+	for j := 0; j < 50; j++ {
+		var f []byte
+		if j%2 == 0 {
+			f = []byte(fmt.Sprintf("{\"kid\":\"key-%d\"}", j))
+		}
+		i := []byte(fmt.Sprintf("{\"id\":%d}", j))
+
+		token, err := Encrypt(rand.Reader, oldKey, []byte(fmt.Sprintf("message-%d", j)), f, i)
+		assert.NoError(t, err)
+
+		id := fmt.Sprintf("%d", j)
+		tokens[id] = StoredToken{ID: id, Token: token, Implicit: i}
+	}
+
+	in := make(chan StoredToken)
+	out := make(chan StoredToken, len(tokens))
+	go func() {
+		defer close(in)
+		for _, st := range tokens {
+			in <- st
+		}
+	}()
+
+	err = Rewrap(context.Background(), rand.Reader, oldKey, newKey, in, out, 4)
+	assert.NoError(t, err)
+	close(out)
+
+	count := 0
+	for st := range out {
+		count++
+		original := tokens[st.ID]
+
+		// Footer is preserved
+		_, footer, err := decodeToken(LocalPrefix, st.Token)
+		assert.NoError(t, err)
+		_, originalFooter, err := decodeToken(LocalPrefix, original.Token)
+		assert.NoError(t, err)
+		assert.Equal(t, originalFooter, footer)
+
+		// Payload is preserved
+		expected, err := Decrypt(oldKey, original.Token, originalFooter, original.Implicit)
+		assert.NoError(t, err)
+		m, err := Decrypt(newKey, st.Token, footer, st.Implicit)
+		assert.NoError(t, err)
+		assert.Equal(t, expected, m)
+
+		// Old key doesn't work anymore
+		_, err = Decrypt(oldKey, st.Token, footer, st.Implicit)
+		assert.Error(t, err)
+	}
+	assert.Equal(t, len(tokens), count)
+}
+
+func Test_Paseto_Rewrap_Error(t *testing.T) {
+	oldKey, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	newKey, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Token encrypted with another key
+	token, err := Encrypt(rand.Reader, newKey, []byte("message"), nil, nil)
+	assert.NoError(t, err)
+
+	in := make(chan StoredToken, 1)
+	in <- StoredToken{ID: "foreign", Token: token}
+
+	// The input channel is never closed, the error must stop the workers
+	err = Rewrap(context.Background(), rand.Reader, oldKey, newKey, in, make(chan StoredToken), 2)
+	assert.ErrorContains(t, err, "foreign")
+
+	// Random source failure
+	token, err = Encrypt(rand.Reader, oldKey, []byte("message"), nil, nil)
+	assert.NoError(t, err)
+	errRandom := errors.New("random source failure")
+	valid := make(chan StoredToken, 1)
+	valid <- StoredToken{ID: "valid", Token: token}
+	err = Rewrap(context.Background(), iotest.ErrReader(errRandom), oldKey, newKey, valid, make(chan StoredToken), 1)
+	assert.ErrorIs(t, err, errRandom)
+
+	// Cancelled context
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err = Rewrap(ctx, rand.Reader, oldKey, newKey, make(chan StoredToken), make(chan StoredToken), 2)
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// Invalid arguments
+	assert.ErrorIs(t, Rewrap(context.Background(), rand.Reader, nil, newKey, in, nil, 1), ErrNilKey)
+	assert.Error(t, Rewrap(context.Background(), rand.Reader, oldKey, newKey, nil, nil, 1))
+	assert.Error(t, Rewrap(context.Background(), nil, oldKey, newKey, in, make(chan StoredToken), 1))
+}
+
+func Test_Paseto_Rewrap_ErrorDoesNotBlockProducer(t *testing.T) {
+	oldKey, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	newKey, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Token encrypted with another key
+	token, err := Encrypt(rand.Reader, newKey, []byte("message"), nil, nil)
+	assert.NoError(t, err)
+
+	// Unbuffered producer sending more tokens than the workers can take
+	in := make(chan StoredToken)
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defer close(in)
+		for j := 0; j < 10; j++ {
+			in <- StoredToken{ID: fmt.Sprintf("foreign-%d", j), Token: token}
+		}
+	}()
+
+	err = Rewrap(context.Background(), rand.Reader, oldKey, newKey, in, make(chan StoredToken), 2)
+	assert.ErrorContains(t, err, "foreign")
+
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("producer is blocked after Rewrap returned")
+	}
+}
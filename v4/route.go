@@ -0,0 +1,128 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"unicode/utf8"
+
+	"zntr.io/paseto/claims"
+	"zntr.io/paseto/internal/common"
+)
+
+// ErrMissingKeyID is raised when a token footer doesn't declare a key
+// identifier (`kid`).
+var ErrMissingKeyID = errors.New("paseto: footer has no key identifier")
+
+// RouteKey returns the key identifier (`kid`) declared in the JSON footer of a
+// PASETO v4 local or public token, without decrypting nor verifying it. It is
+// intended to select a backend or a key from a routing table before the token
+// is processed.
+//
+// The body is neither decoded nor authenticated, and the returned identifier
+// is attacker controlled: it must only be used as a lookup key, the token must
+// still be decrypted or verified with the selected key.
+//
+// ErrMissingFooter is raised when the token has no footer, ErrFooterNotJSON
+// when the footer is not JSON and ErrMissingKeyID when the footer has no
+// `kid`.
+func RouteKey(token string) (string, error) {
+	// Check token header
+	var prefix string
+	switch {
+	case strings.HasPrefix(token, LocalPrefix):
+		prefix = LocalPrefix
+	case strings.HasPrefix(token, PublicPrefix):
+		prefix = PublicPrefix
+	default:
+		return "", common.CheckHeader([]byte(token), LocalPrefix)
+	}
+
+	// Extract the footer
+	_, rawFooter, ok := strings.Cut(token[len(prefix):], ".")
+	if !ok || rawFooter == "" {
+		return "", ErrMissingFooter
+	}
+	if strings.ContainsAny(rawFooter, ".\r\n") {
+		return "", fmt.Errorf("%w, unexpected segment or line break", ErrInvalidToken)
+	}
+
+	// Check footer size before decoding
+	if err := common.CheckFooterLength([]byte(rawFooter), MaxFooterLength); err != nil {
+		return "", err
+	}
+
+	// Decode footer
+	footer, err := base64.RawURLEncoding.DecodeString(rawFooter)
+	if err != nil {
+		return "", fmt.Errorf("%w, footer has invalid encoding: %v", ErrInvalidToken, err)
+	}
+
+	// Fast path for the canonical `{"kid":"..."}` footer
+	if kid, ok := canonicalKeyID(footer); ok {
+		return kid, nil
+	}
+
+	// Fallback to the JSON decoder
+	var f claims.Footer
+	if err := json.Unmarshal(footer, &f); err != nil {
+		return "", ErrFooterNotJSON
+	}
+	if f.KeyID == "" {
+		return "", ErrMissingKeyID
+	}
+
+	// No error
+	return f.KeyID, nil
+}
+
+// -----------------------------------------------------------------------------
+
+const (
+	canonicalKeyIDPrefix = `{"kid":"`
+	canonicalKeyIDSuffix = `"}`
+)
+
+// canonicalKeyID extracts the key identifier from a footer which is exactly
+// `{"kid":"..."}` with an identifier that doesn't need unescaping, it returns
+// false for any other footer so that the JSON decoder handles it.
+func canonicalKeyID(footer []byte) (string, bool) {
+	if len(footer) <= len(canonicalKeyIDPrefix)+len(canonicalKeyIDSuffix) {
+		return "", false
+	}
+	if string(footer[:len(canonicalKeyIDPrefix)]) != canonicalKeyIDPrefix ||
+		string(footer[len(footer)-len(canonicalKeyIDSuffix):]) != canonicalKeyIDSuffix {
+		return "", false
+	}
+
+	kid := footer[len(canonicalKeyIDPrefix) : len(footer)-len(canonicalKeyIDSuffix)]
+	for _, c := range kid {
+		if c == '"' || c == '\\' || c < 0x20 {
+			return "", false
+		}
+	}
+	if !utf8.Valid(kid) {
+		return "", false
+	}
+
+	return string(kid), true
+}
@@ -0,0 +1,122 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	"zntr.io/paseto/claims"
+)
+
+func Test_RouteKey(t *testing.T) {
+	withFooter := func(prefix, footer string) string {
+		return prefix + "AAAA." + base64.RawURLEncoding.EncodeToString([]byte(footer))
+	}
+
+	testCases := []struct {
+		name    string
+		token   string
+		want    string
+		wantErr bool
+		errIs   error
+	}{
+		{name: "local", token: withFooter(LocalPrefix, `{"kid":"k4.lid.abc"}`), want: "k4.lid.abc"},
+		{name: "public", token: withFooter(PublicPrefix, `{"kid":"k4.pid.abc"}`), want: "k4.pid.abc"},
+		{name: "additional fields", token: withFooter(LocalPrefix, `{"wpk":"x","kid":"k1"}`), want: "k1"},
+		{name: "whitespaces", token: withFooter(LocalPrefix, `{ "kid" : "k1" }`), want: "k1"},
+		{name: "escaped", token: withFooter(LocalPrefix, `{"kid":"k\"1"}`), want: `k"1`},
+		{name: "non ascii", token: withFooter(LocalPrefix, `{"kid":"é"}`), want: "é"},
+		{name: "no footer", token: LocalPrefix + "AAAA", wantErr: true, errIs: ErrMissingFooter},
+		{name: "empty footer", token: LocalPrefix + "AAAA.", wantErr: true, errIs: ErrMissingFooter},
+		{name: "no kid", token: withFooter(LocalPrefix, `{"gen":"x"}`), wantErr: true, errIs: ErrMissingKeyID},
+		{name: "empty kid", token: withFooter(LocalPrefix, `{"kid":""}`), wantErr: true, errIs: ErrMissingKeyID},
+		{name: "null footer", token: withFooter(LocalPrefix, `null`), wantErr: true, errIs: ErrMissingKeyID},
+		{name: "not json", token: withFooter(LocalPrefix, `kid=k1`), wantErr: true, errIs: ErrFooterNotJSON},
+		{name: "truncated json", token: withFooter(LocalPrefix, `{"kid":"k1"`), wantErr: true, errIs: ErrFooterNotJSON},
+		{name: "invalid encoding", token: LocalPrefix + "AAAA.!!!", wantErr: true, errIs: ErrInvalidToken},
+		{name: "extra segment", token: withFooter(LocalPrefix, `{"kid":"k1"}`) + ".AAAA", wantErr: true, errIs: ErrInvalidToken},
+		{name: "line break", token: withFooter(LocalPrefix, `{"kid":"k1"}`) + "\n", wantErr: true, errIs: ErrInvalidToken},
+		{name: "wrong version", token: withFooter("v3.local.", `{"kid":"k1"}`), wantErr: true, errIs: ErrWrongVersion},
+		{name: "too large", token: withFooter(LocalPrefix, `{"kid":"`+strings.Repeat("a", MaxFooterLength)+`"}`), wantErr: true, errIs: ErrFooterTooLarge},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			kid, err := RouteKey(testCase.token)
+			if testCase.wantErr {
+				assert.ErrorIs(t, err, testCase.errIs)
+				assert.Empty(t, kid)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, kid)
+		})
+	}
+
+	// Real token
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	token, err := EncryptWithAutoKID(rand.Reader, key, []byte("message"), nil)
+	assert.NoError(t, err)
+	expected, err := LocalKeyID(key)
+	assert.NoError(t, err)
+	kid, err := RouteKey(token)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, kid)
+}
+
+func Test_RouteKey_CanonicalMatchesDecoder(t *testing.T) {
+	for _, footer := range []string{
+		`{"kid":"k4.lid.abc"}`,
+		`{"kid":"é"}`,
+		`{"kid":"a\"b"}`,
+		`{"kid":"a\\b"}`,
+		`{"kid":"a` + "\x01" + `b"}`,
+		`{"kid":"a` + "\xff" + `b"}`,
+		`{"kid":"a"}"}`,
+		`{"kid":""}`,
+	} {
+		kid, ok := canonicalKeyID([]byte(footer))
+		if !ok {
+			continue
+		}
+
+		var f claims.Footer
+		assert.NoError(t, json.Unmarshal([]byte(footer), &f), footer)
+		assert.Equal(t, f.KeyID, kid, footer)
+	}
+}
+
+func Benchmark_Paseto_RouteKey(b *testing.B) {
+	t := "v4.local.32VIErrEkmY4JVILovbmfPXKW9wT1OdQepjMTC_MOtjA4kiqw7_tcaOM5GNEcnTxl60WiA8rd3wgFSNb_UdJPXjpzm0KW9ojM5f4O2mRvE2IcweP-PRdoHjd5-RHCiExR1IK6t5uvqQbMGlLLNYBc7A6_x7oqnpUK5WLvj24eE4DVPDZjw.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9"
+
+	b.ReportAllocs()
+	b.ResetTimer()
+
+	for i := 0; i < b.N; i++ {
+		if _, err := RouteKey(t); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
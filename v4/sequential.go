@@ -0,0 +1,83 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"encoding/binary"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"zntr.io/paseto/internal/common"
+	"zntr.io/paseto/observer"
+)
+
+// SequentialEncryptor encrypts tokens with nonces mixing a monotonic counter
+// and random bytes.
+//
+// It is a defense-in-depth against RNG failures (poor entropy on embedded
+// devices): the nonce is `counter (8 bytes) || random (24 bytes)`, so two
+// tokens from the same encryptor never share a nonce even if the random source
+// repeats. The counter starts at the current time in nanoseconds to avoid
+// reusing counter values after a restart, as long as the clock doesn't go
+// backward. Nonces from different encryptors sharing a key only rely on the
+// random part, use a single encryptor per key. It is safe for concurrent use.
+type SequentialEncryptor struct {
+	key     *LocalKey
+	opts    options
+	counter atomic.Uint64
+}
+
+// NewSequentialEncryptor creates a sequential encryptor for the given key. The
+// random part of the nonces is read from the source set with
+// WithRandomSource (crypto/rand by default).
+func NewSequentialEncryptor(key *LocalKey, opts ...Option) *SequentialEncryptor {
+	e := &SequentialEncryptor{
+		key:  key,
+		opts: newOptions(opts),
+	}
+	e.counter.Store(uint64(time.Now().UnixNano()))
+
+	return e
+}
+
+// Encrypt encrypts the message (m) like Encrypt with a sequential nonce.
+func (e *SequentialEncryptor) Encrypt(m, f, i []byte) (string, error) {
+	return trackString(e.opts.observer, observer.OpEncrypt, func() (string, error) {
+		return e.encrypt(m, f, i)
+	})
+}
+
+// -----------------------------------------------------------------------------
+
+func (e *SequentialEncryptor) encrypt(m, f, i []byte) (string, error) {
+	// Check arguments
+	if e.key == nil {
+		return "", ErrNilKey
+	}
+
+	// Prepare nonce
+	var n [nonceLength]byte
+	binary.BigEndian.PutUint64(n[:8], e.counter.Add(1))
+	if err := common.ReadRandom(e.opts.rand, n[8:]); err != nil {
+		return "", fmt.Errorf("paseto: unable to generate random seed: %w", err)
+	}
+
+	// No error
+	return encrypt(n[:], e.key, m, f, i)
+}
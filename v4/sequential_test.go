@@ -0,0 +1,91 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bytes"
+	"crypto/rand"
+	"io"
+	"sync"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_SequentialEncryptor(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Simulate a broken random source
+	e := NewSequentialEncryptor(key, WithRandomSource(zeroReader{}))
+
+	m := []byte("{\"data\":\"this is a secret message\"}")
+
+	var mu sync.Mutex
+	seen := map[string]struct{}{}
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for n := 0; n < 100; n++ {
+				token, err := e.Encrypt(m, nil, nil)
+				assert.NoError(t, err)
+
+				p, err := Decrypt(key, token, nil, nil)
+				assert.NoError(t, err)
+				assert.Equal(t, m, p)
+
+				nonce, err := ExtractNonce(token)
+				assert.NoError(t, err)
+
+				mu.Lock()
+				seen[string(nonce)] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Nonces are unique even with a constant random source
+	assert.Len(t, seen, 800)
+
+	_, err = NewSequentialEncryptor(nil).Encrypt(m, nil, nil)
+	assert.ErrorIs(t, err, ErrNilKey)
+}
+
+func Test_Paseto_SequentialEncryptor_RandomSource(t *testing.T) {
+	key, err := GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	// Each token reads 24 random bytes from the configured source
+	e := NewSequentialEncryptor(key, WithRandomSource(bytes.NewReader(make([]byte, 24))))
+	_, err = e.Encrypt([]byte("message"), nil, nil)
+	assert.NoError(t, err)
+	_, err = e.Encrypt([]byte("message"), nil, nil)
+	assert.ErrorIs(t, err, io.ErrUnexpectedEOF)
+}
+
+// -----------------------------------------------------------------------------
+
+type zeroReader struct{}
+
+func (zeroReader) Read(p []byte) (int, error) {
+	clear(p)
+	return len(p), nil
+}
@@ -0,0 +1,140 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+
+	"zntr.io/paseto/internal/common"
+	"zntr.io/paseto/observer"
+)
+
+// MaxStreamLineLength is the maximum line length accepted by VerifyStream.
+const MaxStreamLineLength = 1 << 20
+
+// VerifyStream reads newline-delimited public tokens from the reader and
+// verifies them one by one with the given public key and implicit assertion
+// (i). The callback is invoked for each non-blank line with the payload and
+// the footer, or the verification error.
+//
+// The decoding buffer is reused between the tokens: payload and footer are
+// only valid during the callback, copy them to retain them.
+//
+// The returned error is a reading error, a line larger than
+// MaxStreamLineLength stops the processing with bufio.ErrTooLong. Each token
+// verification is reported to the observer set with WithObserver.
+func VerifyStream(r io.Reader, pk ed25519.PublicKey, i []byte, fn func(payload, footer []byte, err error), opts ...Option) error {
+	// Check arguments
+	if r == nil {
+		return errors.New("paseto: reader must not be nil")
+	}
+	if fn == nil {
+		return errors.New("paseto: callback must not be nil")
+	}
+
+	o := newOptions(opts).observer
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 4096), MaxStreamLineLength)
+
+	var buf []byte
+	for scanner.Scan() {
+		line := bytes.TrimSpace(scanner.Bytes())
+		if len(line) == 0 {
+			continue
+		}
+
+		var (
+			m, footer []byte
+			err       error
+		)
+		err = observer.Track(o, observer.OpVerify, "v4", func() error {
+			var err error
+			m, footer, buf, err = verifyLine(line, pk, i, buf)
+			return err
+		})
+		fn(m, footer, err)
+	}
+
+	return scanner.Err()
+}
+
+// -----------------------------------------------------------------------------
+
+// verifyLine verifies a public token, the body and the footer are decoded in
+// the given buffer which is returned for reuse.
+func verifyLine(token []byte, pk ed25519.PublicKey, i, buf []byte) (m, footer, out []byte, err error) {
+	// Check token header
+	if err := common.CheckHeader(token, PublicPrefix); err != nil {
+		return nil, nil, buf, err
+	}
+
+	// Split the footer and the body
+	rawBody, rawFooter, err := common.SplitToken(token[len(PublicPrefix):])
+	if err != nil {
+		return nil, nil, buf, err
+	}
+
+	// Check footer size before decoding
+	if err := common.CheckFooterLength(rawFooter, MaxFooterLength); err != nil {
+		return nil, nil, buf, err
+	}
+
+	// Grow the buffer
+	bodyLen := base64.RawURLEncoding.DecodedLen(len(rawBody))
+	footerLen := base64.RawURLEncoding.DecodedLen(len(rawFooter))
+	if cap(buf) < bodyLen+footerLen {
+		buf = make([]byte, bodyLen+footerLen)
+	}
+	buf = buf[:bodyLen+footerLen]
+
+	// Decode body
+	n, err := base64.RawURLEncoding.Decode(buf[:bodyLen], rawBody)
+	if err != nil {
+		return nil, nil, buf, fmt.Errorf("%w body: %v", ErrInvalidToken, err)
+	}
+	raw := buf[:n]
+
+	// Decode footer
+	if len(rawFooter) > 0 {
+		n, err := base64.RawURLEncoding.Decode(buf[bodyLen:], rawFooter)
+		if err != nil {
+			return nil, nil, buf, fmt.Errorf("%w, footer has invalid encoding: %v", ErrInvalidToken, err)
+		}
+		footer = buf[bodyLen : bodyLen+n]
+	}
+
+	// Check body length
+	if len(raw) < ed25519.SignatureSize {
+		return nil, nil, buf, fmt.Errorf("%w body, signature is missing", ErrInvalidToken)
+	}
+
+	// Check signature
+	m = raw[:len(raw)-ed25519.SignatureSize]
+	if err := VerifyDetached(m, raw[len(raw)-ed25519.SignatureSize:], pk, footer, i); err != nil {
+		return nil, nil, buf, err
+	}
+
+	// No error
+	return m, footer, buf, nil
+}
@@ -0,0 +1,88 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"bufio"
+	"crypto/ed25519"
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_Paseto_VerifyStream(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	otherPk, _, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+
+	i := []byte("implicit")
+
+	var lines []string
+	for j := 0; j < 3; j++ {
+		token, err := Sign([]byte(fmt.Sprintf("payload-%d", j)), sk, []byte(fmt.Sprintf("footer-%d", j)), i)
+		assert.NoError(t, err)
+		lines = append(lines, token)
+	}
+	unsigned, err := Sign([]byte("other"), sk, nil, nil)
+	assert.NoError(t, err)
+	lines = append(lines, "", "v4.local.AAAA", unsigned, "  "+lines[0]+"\r")
+
+	type result struct {
+		payload, footer string
+		err             error
+	}
+	var results []result
+	err = VerifyStream(strings.NewReader(strings.Join(lines, "\n")), pk, i, func(payload, footer []byte, err error) {
+		// Buffers are reused, copy them
+		results = append(results, result{payload: string(payload), footer: string(footer), err: err})
+	})
+	assert.NoError(t, err)
+
+	// Blank lines are skipped
+	assert.Len(t, results, 6)
+	for j := 0; j < 3; j++ {
+		assert.NoError(t, results[j].err)
+		assert.Equal(t, fmt.Sprintf("payload-%d", j), results[j].payload)
+		assert.Equal(t, fmt.Sprintf("footer-%d", j), results[j].footer)
+	}
+	assert.ErrorIs(t, results[3].err, ErrInvalidToken)
+	assert.ErrorIs(t, results[4].err, ErrInvalidSignature)
+	assert.NoError(t, results[5].err)
+	assert.Equal(t, "payload-0", results[5].payload)
+
+	// Wrong key
+	count := 0
+	err = VerifyStream(strings.NewReader(lines[0]), otherPk, i, func(_, _ []byte, err error) {
+		count++
+		assert.ErrorIs(t, err, ErrInvalidSignature)
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, 1, count)
+
+	// Line too long
+	err = VerifyStream(strings.NewReader(strings.Repeat("A", MaxStreamLineLength+1)), pk, i, func(_, _ []byte, _ error) {})
+	assert.ErrorIs(t, err, bufio.ErrTooLong)
+
+	// Invalid arguments
+	assert.Error(t, VerifyStream(nil, pk, i, func(_, _ []byte, _ error) {}))
+	assert.Error(t, VerifyStream(strings.NewReader(""), pk, i, nil))
+}
@@ -0,0 +1,155 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	_ "embed"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+// Vectors from https://github.com/paseto-standard/test-vectors/blob/master/v4.json
+//
+//go:embed testdata/v4.json
+var testVectorsJSON []byte
+
+// Local negative vectors, not part of the upstream file. They cover a wrong
+// purpose, a foreign version header, a tampered tag or signature, footer and
+// implicit assertion mismatches and invalid key lengths, derived from the
+// 4-E-*/4-S-* vectors.
+//
+//go:embed testdata/v4-negative.json
+var negativeVectorsJSON []byte
+
+type testVector struct {
+	Name              string  `json:"name"`
+	ExpectFail        bool    `json:"expect-fail"`
+	Key               string  `json:"key"`
+	Nonce             string  `json:"nonce"`
+	PublicKey         string  `json:"public-key"`
+	SecretKey         string  `json:"secret-key"`
+	Token             string  `json:"token"`
+	Payload           *string `json:"payload"`
+	Footer            string  `json:"footer"`
+	ImplicitAssertion string  `json:"implicit-assertion"`
+}
+
+func loadTestVectors(t *testing.T, raw []byte) []testVector {
+	t.Helper()
+
+	var file struct {
+		Tests []testVector `json:"tests"`
+	}
+	if err := json.Unmarshal(raw, &file); err != nil {
+		t.Fatalf("unable to decode test vectors: %v", err)
+	}
+	if len(file.Tests) == 0 {
+		t.Fatal("no test vector found")
+	}
+
+	return file.Tests
+}
+
+func Test_Paseto_ConformanceVectors(t *testing.T) {
+	runVectors(t, loadTestVectors(t, testVectorsJSON))
+}
+
+func Test_Paseto_NegativeVectors(t *testing.T) {
+	vectors := loadTestVectors(t, negativeVectorsJSON)
+	for _, tc := range vectors {
+		assert.True(t, tc.ExpectFail, "%s must be an expect-fail vector", tc.Name)
+	}
+
+	runVectors(t, vectors)
+}
+
+func runVectors(t *testing.T, vectors []testVector) {
+	for _, tc := range vectors {
+		testCase := tc
+		t.Run(testCase.Name, func(t *testing.T) {
+			f := []byte(testCase.Footer)
+			i := []byte(testCase.ImplicitAssertion)
+
+			if testCase.Key != "" {
+				runLocalVector(t, &testCase, f, i)
+			} else {
+				runPublicVector(t, &testCase, f, i)
+			}
+		})
+	}
+}
+
+func runLocalVector(t *testing.T, testCase *testVector, f, i []byte) {
+	// Decode key
+	var key LocalKey
+	keyRaw, err := hex.DecodeString(testCase.Key)
+	if err != nil || len(keyRaw) != KeyLength {
+		assert.True(t, testCase.ExpectFail, "invalid key for a successful vector")
+		return
+	}
+	copy(key[:], keyRaw)
+
+	if testCase.ExpectFail {
+		_, err := Decrypt(&key, testCase.Token, f, i)
+		assert.Error(t, err)
+		return
+	}
+
+	// Encrypt
+	n, err := hex.DecodeString(testCase.Nonce)
+	assert.NoError(t, err)
+	token, err := EncryptWithNonce(&key, n, []byte(*testCase.Payload), f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, testCase.Token, token)
+
+	// Decrypt
+	message, err := Decrypt(&key, testCase.Token, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, *testCase.Payload, string(message))
+}
+
+func runPublicVector(t *testing.T, testCase *testVector, f, i []byte) {
+	// Decode public key
+	pk, err := hex.DecodeString(testCase.PublicKey)
+	if err != nil || len(pk) != ed25519.PublicKeySize {
+		assert.True(t, testCase.ExpectFail, "invalid public key for a successful vector")
+		return
+	}
+
+	if testCase.ExpectFail {
+		_, err := Verify(testCase.Token, pk, f, i)
+		assert.Error(t, err)
+		return
+	}
+
+	// Sign
+	sk, err := hex.DecodeString(testCase.SecretKey)
+	assert.NoError(t, err)
+	token, err := Sign([]byte(*testCase.Payload), sk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, testCase.Token, token)
+
+	// Verify
+	message, err := Verify(testCase.Token, pk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, *testCase.Payload, string(message))
+}
@@ -0,0 +1,114 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+)
+
+// ErrWeakPublicKey is raised when a public key is a small-order point.
+var ErrWeakPublicKey = errors.New("paseto: weak public key, small-order point")
+
+// smallOrderPoints lists the encodings of the Edwards25519 points of order 1,
+// 2, 4 and 8, including the non-canonical ones (y >= p). The sign bit is
+// ignored during the comparison.
+var smallOrderPoints = [][ed25519.PublicKeySize]byte{
+	// 0 (order 4)
+	{
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	},
+	// 1 (order 1, identity)
+	{
+		0x01, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+		0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00, 0x00,
+	},
+	// order 8
+	{
+		0x26, 0xe8, 0x95, 0x8f, 0xc2, 0xb2, 0x27, 0xb0, 0x45, 0xc3, 0xf4, 0x89, 0xf2, 0xef, 0x98, 0xf0,
+		0xd5, 0xdf, 0xac, 0x05, 0xd3, 0xc6, 0x33, 0x39, 0xb1, 0x38, 0x02, 0x88, 0x6d, 0x53, 0xfc, 0x05,
+	},
+	// order 8
+	{
+		0xc7, 0x17, 0x6a, 0x70, 0x3d, 0x4d, 0xd8, 0x4f, 0xba, 0x3c, 0x0b, 0x76, 0x0d, 0x10, 0x67, 0x0f,
+		0x2a, 0x20, 0x53, 0xfa, 0x2c, 0x39, 0xcc, 0xc6, 0x4e, 0xc7, 0xfd, 0x77, 0x92, 0xac, 0x03, 0x7a,
+	},
+	// p-1 (order 2)
+	{
+		0xec, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+	},
+	// p (non-canonical 0, order 4)
+	{
+		0xed, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+	},
+	// p+1 (non-canonical 1, order 1)
+	{
+		0xee, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff,
+		0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0x7f,
+	},
+}
+
+// ValidatePublicKey rejects the Ed25519 public keys encoding a small-order
+// point.
+//
+// A small-order public key admits signatures that verify for many messages
+// (the signer doesn't need the private key), which breaks protocols relying
+// on signatures being bound to a unique key (non-repudiation, key
+// commitment, contributory behaviour). Honestly generated keys are never
+// small-order, but the verification itself doesn't reject them.
+//
+// This check is opt-in: call it when importing a public key, or use
+// VerifyStrict.
+func ValidatePublicKey(pk ed25519.PublicKey) error {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PublicKeySize)
+	}
+
+	// Clear the sign bit
+	var y [ed25519.PublicKeySize]byte
+	copy(y[:], pk)
+	y[31] &= 0x7f
+
+	found := 0
+	for _, p := range smallOrderPoints {
+		found |= subtle.ConstantTimeCompare(y[:], p[:])
+	}
+	if found == 1 {
+		return ErrWeakPublicKey
+	}
+
+	// No error
+	return nil
+}
+
+// VerifyStrict verifies the token like Verify but rejects the small-order
+// public keys with ErrWeakPublicKey before the signature verification.
+func VerifyStrict(t string, pk ed25519.PublicKey, f, i []byte) ([]byte, error) {
+	// Check public key
+	if err := ValidatePublicKey(pk); err != nil {
+		return nil, err
+	}
+
+	return Verify(t, pk, f, i)
+}
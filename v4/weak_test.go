@@ -0,0 +1,55 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func Test_ValidatePublicKey(t *testing.T) {
+	pk, sk, err := ed25519.GenerateKey(rand.Reader)
+	assert.NoError(t, err)
+	assert.NoError(t, ValidatePublicKey(pk))
+
+	for _, p := range smallOrderPoints {
+		weak := ed25519.PublicKey(append([]byte{}, p[:]...))
+		assert.ErrorIs(t, ValidatePublicKey(weak), ErrWeakPublicKey)
+
+		// Sign bit is ignored
+		weak[31] |= 0x80
+		assert.ErrorIs(t, ValidatePublicKey(weak), ErrWeakPublicKey)
+	}
+
+	assert.ErrorIs(t, ValidatePublicKey(pk[:16]), ErrInvalidKeyLength)
+
+	// Strict verification
+	token, err := Sign([]byte("payload"), sk, nil, nil)
+	assert.NoError(t, err)
+
+	m, err := VerifyStrict(token, pk, nil, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, []byte("payload"), m)
+
+	var identity ed25519.PublicKey = append([]byte{}, smallOrderPoints[1][:]...)
+	_, err = VerifyStrict(token, identity, nil, nil)
+	assert.ErrorIs(t, err, ErrWeakPublicKey)
+}
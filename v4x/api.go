@@ -17,9 +17,61 @@
 
 package v4x
 
+import (
+	"crypto/subtle"
+
+	"zntr.io/paseto/internal/common"
+)
+
+var (
+	// ErrNilKey is raised when a nil key is given.
+	ErrNilKey = common.ErrNilKey
+	// ErrInvalidKeyLength is raised when a key or seed has an invalid length.
+	ErrInvalidKeyLength = common.ErrInvalidKeyLength
+	// ErrInvalidToken is raised when a token is malformed (header, segments,
+	// encoding or length).
+	ErrInvalidToken = common.ErrInvalidToken
+	// ErrMissingFooter is raised when a footer is expected but the token
+	// doesn't have one. It wraps ErrInvalidToken.
+	ErrMissingFooter = common.ErrMissingFooter
+	// ErrWrongVersion is raised when the token has another PASETO version. It
+	// wraps ErrInvalidToken.
+	ErrWrongVersion = common.ErrWrongVersion
+	// ErrWrongPurpose is raised when the token has another PASETO purpose. It
+	// wraps ErrInvalidToken.
+	ErrWrongPurpose = common.ErrWrongPurpose
+	// ErrFooterTooLarge is raised when a token footer exceeds MaxFooterLength.
+	ErrFooterTooLarge = common.ErrFooterTooLarge
+	// ErrInvalidMAC is raised when the token MAC doesn't match (tampered token,
+	// wrong key, footer or implicit assertion).
+	ErrInvalidMAC = common.ErrInvalidMAC
+	// ErrInvalidSignature is raised when the token signature doesn't match
+	// (tampered token, wrong key, footer or implicit assertion).
+	ErrInvalidSignature = common.ErrInvalidSignature
+	// ErrPossibleImplicitMismatch is wrapped with ErrInvalidMAC and
+	// ErrInvalidSignature when an implicit assertion was given.
+	ErrPossibleImplicitMismatch = common.ErrPossibleImplicitMismatch
+)
+
+// MaxFooterLength is the maximum decoded footer length accepted by the decrypt
+// and verify functions, checked before the footer is decoded to protect public
+// endpoints against oversized tokens. A value lower or equal to 0 disables the
+// check. It must be set during the program initialization, before any
+// concurrent use.
+var MaxFooterLength = DefaultMaxFooterLength
+
 const (
 	// KeyLength is the requested encryption key size.
 	KeyLength = 32
+	// DefaultMaxFooterLength is the default MaxFooterLength value.
+	DefaultMaxFooterLength = 8 * 1024
+)
+
+const (
+	// LocalPrefix is the local purpose (symmetric encryption) token header.
+	LocalPrefix = "v4x.local."
+	// PublicPrefix is the public purpose (asymmetric signature) token header.
+	PublicPrefix = "v4x.public."
 )
 
 const (
@@ -27,8 +79,17 @@ const (
 	macLength               = 32
 	encryptionKDFLength     = 56
 	authenticationKeyLength = 32
-	LocalPrefix             = "v4x.local."
 )
 
 // LocalKey represents a key for symetric encryption (local).
 type LocalKey [32]byte
+
+// Equal reports whether k and other hold the same key material. The key
+// material is compared in constant time.
+func (k *LocalKey) Equal(other *LocalKey) bool {
+	if k == nil || other == nil {
+		return k == other
+	}
+
+	return subtle.ConstantTimeCompare(k[:], other[:]) == 1
+}
@@ -18,20 +18,24 @@
 package v4x
 
 import (
-	"bytes"
 	"crypto/subtle"
 	"encoding/base64"
 	"errors"
 	"fmt"
 	"io"
+	"strings"
 
 	"golang.org/x/crypto/chacha20"
+
+	"zntr.io/paseto/internal/common"
 )
 
 // GenerateLocalKey generates a key for local encryption.
+//
+// It reads exactly KeyLength bytes from r.
 func GenerateLocalKey(r io.Reader) (*LocalKey, error) {
 	var key LocalKey
-	if _, err := io.ReadFull(r, key[:]); err != nil {
+	if err := common.ReadRandom(r, key[:]); err != nil {
 		return nil, fmt.Errorf("paseto: unable to generate a random key: %w", err)
 	}
 
@@ -43,7 +47,7 @@ func GenerateLocalKey(r io.Reader) (*LocalKey, error) {
 func LocalKeyFromSeed(seed []byte) (*LocalKey, error) {
 	// Check minimum seed size.
 	if len(seed) < KeyLength {
-		return nil, fmt.Errorf("paseto: invalid seed length, it must be %d bytes long at least", KeyLength)
+		return nil, fmt.Errorf("%w, seed must be %d bytes long at least", ErrInvalidKeyLength, KeyLength)
 	}
 
 	// Copy data from seed.
@@ -54,87 +58,60 @@ func LocalKeyFromSeed(seed []byte) (*LocalKey, error) {
 	return &key, nil
 }
 
-// PASETO v4 symmetric encryption primitive.
-func Encrypt(r io.Reader, key *LocalKey, m, f, i []byte) (string, error) {
-	// Check arguments
-	if key == nil {
-		return "", errors.New("paseto: key is nil")
-	}
-	if len(key) != KeyLength {
-		return "", fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
-	}
-
-	rawPrefix := []byte(LocalPrefix)
-
-	// Pre-allocate body
-	body := make([]byte, nonceLength+len(m), nonceLength+len(m)+macLength)
-
-	// Create random seed
-	if _, err := io.ReadFull(r, body[:nonceLength]); err != nil {
-		return "", fmt.Errorf("paseto: unable to generate random seed: %w", err)
-	}
-
-	// Derive keys from seed and secret key
-	ek, n2, err := kdf(key, body[:nonceLength])
-	if err != nil {
-		return "", fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
-	}
-
-	// Prepare XChaCha20 stream cipher (nonce > 24bytes => XChacha)
-	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n2)
+// LocalKeyFromHex creates a local key from its hex encoded form. The decoded
+// key must be exactly KeyLength bytes long.
+func LocalKeyFromHex(s string) (*LocalKey, error) {
+	raw, err := common.DecodeHexKey(s, KeyLength)
 	if err != nil {
-		return "", fmt.Errorf("paseto: unable to initialize XChaCha20 cipher: %w", err)
+		return nil, err
 	}
 
-	// Derive authentication key
-	var ak = [32]byte{0x00}
-	ciph.XORKeyStream(ak[:], ak[:])
-
-	// Encrypt the payload
-	ciph.SetCounter(1)
-	ciph.XORKeyStream(body[nonceLength:], m)
+	return LocalKeyFromSeed(raw)
+}
 
-	// Compute MAC
-	t, err := mac(ak[:], rawPrefix, body[:nonceLength], body[nonceLength:], f, i)
+// LocalKeyFromBase64 creates a local key from its base64 encoded form, with
+// the standard or URL-safe alphabet, padded or not. The decoded key must be
+// exactly KeyLength bytes long.
+func LocalKeyFromBase64(s string) (*LocalKey, error) {
+	raw, err := common.DecodeBase64Key(s, KeyLength)
 	if err != nil {
-		return "", fmt.Errorf("paseto: unable to compute MAC: %w", err)
+		return nil, err
 	}
 
-	// Serialize final token
-	// h || base64url(n || c || t)
-	body = append(body, t...)
+	return LocalKeyFromSeed(raw)
+}
 
-	// Encode body as RawURLBase64
-	tokenLen := base64.RawURLEncoding.EncodedLen(len(body))
-	footerLen := 0
-	if len(f) > 0 {
-		footerLen = base64.RawURLEncoding.EncodedLen(len(f)) + 1
-		tokenLen += footerLen
+// PASETO v4 symmetric encryption primitive.
+//
+// It reads exactly 32 bytes (the nonce) from r.
+func Encrypt(r io.Reader, key *LocalKey, m, f, i []byte) (string, error) {
+	// Create random seed
+	var n [nonceLength]byte
+	if err := common.ReadRandom(r, n[:]); err != nil {
+		return "", fmt.Errorf("paseto: unable to generate random seed: %w", err)
 	}
 
-	final := make([]byte, len(LocalPrefix)+tokenLen)
-	copy(final, rawPrefix)
-	base64.RawURLEncoding.Encode(final[10:], body)
-
-	// Assemble final token
-	if len(f) > 0 {
-		final[10+tokenLen-footerLen] = '.'
-		// Encode footer as RawURLBase64
-		base64.RawURLEncoding.Encode(final[10+tokenLen-footerLen+1:], f)
-	}
+	return encrypt(n[:], key, m, f, i)
+}
 
-	// No error
-	return string(final), nil
+// EncryptWithNonce encrypts the message (m) using the given nonce instead of a
+// random one.
+//
+// It is intended for testing and conformance checks against the published test
+// vectors only. Reusing a nonce breaks the confidentiality of the tokens, it
+// must never be used in production code.
+func EncryptWithNonce(key *LocalKey, nonce, m, f, i []byte) (string, error) {
+	return encrypt(nonce, key, m, f, i)
 }
 
 // PASETO v4 symmetric decryption primitive
 func Decrypt(key *LocalKey, input string, f, i []byte) ([]byte, error) {
 	// Check arguments
 	if key == nil {
-		return nil, errors.New("paseto: key is nil")
+		return nil, ErrNilKey
 	}
 	if len(key) != KeyLength {
-		return nil, fmt.Errorf("paseto: invalid key length, it must be %d bytes long", KeyLength)
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, KeyLength)
 	}
 	if input == "" {
 		return nil, errors.New("paseto: input is blank")
@@ -143,46 +120,53 @@ func Decrypt(key *LocalKey, input string, f, i []byte) ([]byte, error) {
 	rawToken := []byte(input)
 
 	// Check token header
-	if !bytes.HasPrefix(rawToken, []byte(LocalPrefix)) {
-		return nil, errors.New("paseto: invalid token")
+	if err := common.CheckHeader(rawToken, LocalPrefix); err != nil {
+		return nil, err
 	}
 
 	// Trim prefix
 	rawToken = rawToken[len(LocalPrefix):]
 
+	// Split the footer and the body
+	rawBody, rawFooter, err := common.SplitToken(rawToken)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check footer size before decoding
+	if err := common.CheckFooterLength(rawFooter, MaxFooterLength); err != nil {
+		return nil, err
+	}
+
 	// Check footer usage
 	if len(f) > 0 {
-		// Split the footer and the body
-		footerIdx := bytes.Index(rawToken, []byte("."))
-		if footerIdx == 0 {
-			return nil, errors.New("paseto: invalid token, footer is missing but expected")
+		if len(rawFooter) == 0 {
+			return nil, ErrMissingFooter
 		}
 
 		// Decode footer
-		footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawToken[footerIdx+1:])))
-		if _, err := base64.RawURLEncoding.Decode(footer, rawToken[footerIdx+1:]); err != nil {
-			return nil, fmt.Errorf("paseto: invalid token, footer has invalid encoding: %w", err)
+		footer := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawFooter)))
+		if _, err := base64.RawURLEncoding.Decode(footer, rawFooter); err != nil {
+			return nil, fmt.Errorf("%w, footer has invalid encoding: %v", ErrInvalidToken, err)
 		}
 
 		// Compare footer
 		if subtle.ConstantTimeCompare(f, footer) == 0 {
-			return nil, errors.New("paseto: invalid token, footer mismatch")
+			return nil, fmt.Errorf("%w, footer mismatch", ErrInvalidToken)
 		}
-
-		// Continue without footer
-		rawToken = rawToken[:footerIdx]
 	}
 
 	// Decode token
-	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawToken)))
-	if _, err := base64.RawURLEncoding.Decode(raw, rawToken); err != nil {
-		return nil, fmt.Errorf("paseto: invalid token body: %w", err)
+	raw := make([]byte, base64.RawURLEncoding.DecodedLen(len(rawBody)))
+	if _, err := base64.RawURLEncoding.Decode(raw, rawBody); err != nil {
+		return nil, fmt.Errorf("%w body: %v", ErrInvalidToken, err)
 	}
 
 	// Extract components
-	n := raw[:nonceLength]
-	t := raw[len(raw)-macLength:]
-	c := raw[nonceLength : len(raw)-macLength]
+	n, c, t, err := common.SplitLocalBody(raw, nonceLength, macLength)
+	if err != nil {
+		return nil, err
+	}
 
 	// Derive keys from seed and secret key
 	ek, n2, err := kdf(key, n)
@@ -209,7 +193,7 @@ func Decrypt(key *LocalKey, input string, f, i []byte) ([]byte, error) {
 
 	// Time-constant compare MAC
 	if subtle.ConstantTimeCompare(t, t2) == 0 {
-		return nil, errors.New("paseto: invalid pre-authentication header")
+		return nil, common.AuthError(ErrInvalidMAC, i)
 	}
 
 	// Decrypt the payload
@@ -218,3 +202,82 @@ func Decrypt(key *LocalKey, input string, f, i []byte) ([]byte, error) {
 	// No error
 	return c, nil
 }
+
+// IsLocal returns true when the token has the `v4x.local.` header. It only checks
+// the header, the token is neither decoded nor authenticated.
+func IsLocal(token string) bool {
+	return strings.HasPrefix(token, LocalPrefix)
+}
+
+// -----------------------------------------------------------------------------
+
+func encrypt(nonce []byte, key *LocalKey, m, f, i []byte) (string, error) {
+	// Check arguments
+	if key == nil {
+		return "", ErrNilKey
+	}
+	if len(key) != KeyLength {
+		return "", fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, KeyLength)
+	}
+	if len(nonce) != nonceLength {
+		return "", fmt.Errorf("paseto: invalid nonce length, it must be %d bytes long", nonceLength)
+	}
+
+	rawPrefix := []byte(LocalPrefix)
+
+	// Pre-allocate body
+	body := make([]byte, nonceLength+len(m), nonceLength+len(m)+macLength)
+	copy(body, nonce)
+
+	// Derive keys from seed and secret key
+	ek, n2, err := kdf(key, body[:nonceLength])
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to derive keys from seed: %w", err)
+	}
+
+	// Prepare XChaCha20 stream cipher (nonce > 24bytes => XChacha)
+	ciph, err := chacha20.NewUnauthenticatedCipher(ek, n2)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to initialize XChaCha20 cipher: %w", err)
+	}
+
+	// Derive authentication key
+	var ak = [32]byte{0x00}
+	ciph.XORKeyStream(ak[:], ak[:])
+
+	// Encrypt the payload
+	ciph.SetCounter(1)
+	ciph.XORKeyStream(body[nonceLength:], m)
+
+	// Compute MAC
+	t, err := mac(ak[:], rawPrefix, body[:nonceLength], body[nonceLength:], f, i)
+	if err != nil {
+		return "", fmt.Errorf("paseto: unable to compute MAC: %w", err)
+	}
+
+	// Serialize final token
+	// h || base64url(n || c || t)
+	body = append(body, t...)
+
+	// Encode body as RawURLBase64
+	tokenLen := base64.RawURLEncoding.EncodedLen(len(body))
+	footerLen := 0
+	if len(f) > 0 {
+		footerLen = base64.RawURLEncoding.EncodedLen(len(f)) + 1
+		tokenLen += footerLen
+	}
+
+	final := make([]byte, len(LocalPrefix)+tokenLen)
+	copy(final, rawPrefix)
+	base64.RawURLEncoding.Encode(final[10:], body)
+
+	// Assemble final token
+	if len(f) > 0 {
+		final[10+tokenLen-footerLen] = '.'
+		// Encode footer as RawURLBase64
+		base64.RawURLEncoding.Encode(final[10+tokenLen-footerLen+1:], f)
+	}
+
+	// No error
+	return string(final), nil
+}
@@ -0,0 +1,115 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4x
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"zntr.io/paseto/internal/common"
+)
+
+// Sign a message (m) with the private key (sk).
+//
+// The construction is the PASETO v4 public one (Ed25519 over the
+// pre-authentication encoding of the header, the message, the footer and the
+// implicit assertion), only the header changes to `v4x.public.`. The token
+// layout is `v4x.public.` || base64url(m || sig) [ || `.` || base64url(f) ].
+// The header is covered by the signature so a v4x token can't be verified as a
+// v4 token and vice versa.
+func Sign(m []byte, sk ed25519.PrivateKey, f, i []byte) (string, error) {
+	// Check arguments
+	if len(sk) != ed25519.PrivateKeySize {
+		return "", fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PrivateKeySize)
+	}
+
+	// Compute protected content
+	m2 := common.PreAuthenticationEncoding([]byte(PublicPrefix), m, f, i)
+
+	// Sign protected content
+	sig := ed25519.Sign(sk, m2)
+
+	// Prepare content
+	body := make([]byte, 0, len(m)+ed25519.SignatureSize)
+	body = append(body, m...)
+	body = append(body, sig...)
+
+	// Assemble final token
+	var final strings.Builder
+	final.WriteString(PublicPrefix)
+	final.WriteString(base64.RawURLEncoding.EncodeToString(body))
+	if len(f) > 0 {
+		final.WriteByte('.')
+		final.WriteString(base64.RawURLEncoding.EncodeToString(f))
+	}
+
+	// No error
+	return final.String(), nil
+}
+
+// Verify the token signature (t) with the public key (pk) and returns the
+// message.
+func Verify(t string, pk ed25519.PublicKey, f, i []byte) ([]byte, error) {
+	// Check arguments
+	if len(pk) != ed25519.PublicKeySize {
+		return nil, fmt.Errorf("%w, it must be %d bytes long", ErrInvalidKeyLength, ed25519.PublicKeySize)
+	}
+	if t == "" {
+		return nil, errors.New("paseto: input is blank")
+	}
+
+	// Decode token
+	raw, footer, err := common.DecodeToken(PublicPrefix, t, MaxFooterLength)
+	if err != nil {
+		return nil, err
+	}
+
+	// Check footer usage
+	if err := common.CheckFooter(f, footer, false); err != nil {
+		return nil, err
+	}
+
+	// Check body length
+	if len(raw) < ed25519.SignatureSize {
+		return nil, fmt.Errorf("%w body, signature is missing", ErrInvalidToken)
+	}
+
+	// Extract components
+	m := raw[:len(raw)-ed25519.SignatureSize]
+	s := raw[len(raw)-ed25519.SignatureSize:]
+
+	// Compute protected content
+	m2 := common.PreAuthenticationEncoding([]byte(PublicPrefix), m, f, i)
+
+	// Check signature
+	if !ed25519.Verify(pk, m2, s) {
+		return nil, common.AuthError(ErrInvalidSignature, i)
+	}
+
+	// No error
+	return m, nil
+}
+
+// IsPublic returns true when the token has the `v4x.public.` header. It only
+// checks the header, the token is neither decoded nor verified.
+func IsPublic(token string) bool {
+	return strings.HasPrefix(token, PublicPrefix)
+}
@@ -0,0 +1,107 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package v4x
+
+import (
+	"crypto/ed25519"
+	"encoding/hex"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	v4 "zntr.io/paseto/v4"
+)
+
+// Generated with the 4-S-3 test vector inputs, the signature differs from the
+// v4 one since the header is part of the pre-authentication encoding.
+const publicVectorToken = "v4x.public.eyJkYXRhIjoidGhpcyBpcyBhIHNpZ25lZCBtZXNzYWdlIiwiZXhwIjoiMjAyMi0wMS0wMVQwMDowMDowMCswMDowMCJ9hcbUo-S17G0UrItT6mlkKvNG8AVbYWhLamgcMWaNeaIgLG7SwFjnRg27OskhR5MbG-tG1zvYZLPf81POhLtuBA.eyJraWQiOiJ6VmhNaVBCUDlmUmYyc25FY1Q3Z0ZUaW9lQTlDT2NOeTlEZmdMMVc2MGhhTiJ9"
+
+func Test_Paseto_PublicVector(t *testing.T) {
+	sk, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	assert.NoError(t, err)
+	pk := ed25519.PrivateKey(sk).Public().(ed25519.PublicKey)
+
+	m := []byte("{\"data\":\"this is a signed message\",\"exp\":\"2022-01-01T00:00:00+00:00\"}")
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"4-S-3\"}")
+
+	// Ed25519 is deterministic, the wire format is reproducible
+	token, err := Sign(m, sk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, publicVectorToken, token)
+	assert.True(t, IsPublic(token))
+	assert.False(t, IsLocal(token))
+
+	got, err := Verify(token, pk, f, i)
+	assert.NoError(t, err)
+	assert.Equal(t, m, got)
+
+	// The footer is authenticated with the expected one, like v4
+	_, err = Verify(token, pk, nil, i)
+	assert.ErrorIs(t, err, ErrInvalidSignature)
+	v4Signed, err := v4.Sign(m, sk, f, i)
+	assert.NoError(t, err)
+	_, err = v4.Verify(v4Signed, pk, nil, i)
+	assert.ErrorIs(t, err, v4.ErrInvalidSignature)
+
+	// Same body with the v4 header must not verify
+	v4Token := v4.PublicPrefix + token[len(PublicPrefix):]
+	_, err = v4.Verify(v4Token, pk, f, i)
+	assert.ErrorIs(t, err, v4.ErrInvalidSignature)
+}
+
+func Test_Paseto_Public_Invalid(t *testing.T) {
+	sk, err := hex.DecodeString("b4cbfb43df4ce210727d953e4a713307fa19bb7d9f85041438d9e11b942a37741eb9dbbbbc047c03fd70604e0071f0987e16b28b757225c11f00415d0e20b1a2")
+	assert.NoError(t, err)
+	pk := ed25519.PrivateKey(sk).Public().(ed25519.PublicKey)
+
+	f := []byte("{\"kid\":\"zVhMiPBP9fRf2snEcT7gFTioeA9COcNy9DfgL1W60haN\"}")
+	i := []byte("{\"test-vector\":\"4-S-3\"}")
+
+	testCases := []struct {
+		name    string
+		token   string
+		pk      ed25519.PublicKey
+		f       []byte
+		i       []byte
+		wantErr error
+	}{
+		{name: "blank", token: "", pk: pk},
+		{name: "invalid key", token: publicVectorToken, pk: pk[:16], wantErr: ErrInvalidKeyLength},
+		{name: "wrong purpose", token: "v4x.local.AAAA", pk: pk, wantErr: ErrWrongPurpose},
+		{name: "wrong version", token: "v4.public.AAAA", pk: pk, wantErr: ErrWrongVersion},
+		{name: "missing signature", token: "v4x.public.AAAA", pk: pk, wantErr: ErrInvalidToken},
+		{name: "missing footer", token: publicVectorToken[:strings.LastIndexByte(publicVectorToken, '.')], pk: pk, f: f, i: i, wantErr: ErrMissingFooter},
+		{name: "footer mismatch", token: publicVectorToken, pk: pk, f: []byte("{}"), i: i, wantErr: ErrInvalidToken},
+		{name: "implicit mismatch", token: publicVectorToken, pk: pk, f: f, wantErr: ErrInvalidSignature},
+	}
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := Verify(testCase.token, testCase.pk, testCase.f, testCase.i)
+			assert.Error(t, err)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+			}
+		})
+	}
+
+	_, err = Sign([]byte("test"), sk[:32], nil, nil)
+	assert.ErrorIs(t, err, ErrInvalidKeyLength)
+}
@@ -0,0 +1,71 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+)
+
+// ErrDisallowedVersion is raised when a token version is not in the accepted
+// versions list.
+var ErrDisallowedVersion = errors.New("paseto: token version is not allowed")
+
+// CheckVersion checks the token structure and returns its header when the
+// token version is one of the allowed versions (`v4`). It is intended to be
+// called before routing the token to a version specific handler, so that a
+// non-standard variant (`v4x`) is explicitly rejected instead of being
+// accepted by whatever handler matches.
+func CheckVersion(token string, allowed ...string) (Header, error) {
+	// Check token structure
+	prefix, _, _, err := Split(token)
+	if err != nil {
+		return Header{}, err
+	}
+	h, _ := LookupHeader(prefix)
+
+	// Check version
+	if !slices.Contains(allowed, h.Version) {
+		return Header{}, fmt.Errorf("%w: %q", ErrDisallowedVersion, h.Version)
+	}
+
+	// No error
+	return h, nil
+}
+
+// WithAllowedVersions restricts the given key provider to the allowed token
+// versions (`v3`, `v4`, `v4x`), other versions are rejected with
+// ErrDisallowedVersion before any key resolution.
+//
+//	claims, err := paseto.DecodeAndValidate(token, paseto.WithAllowedVersions(keys, "v4"))
+func WithAllowedVersions(keys KeyProvider, allowed ...string) KeyProvider {
+	return KeyProviderFunc(func(h Header, kid string) (any, error) {
+		// Check version
+		if !slices.Contains(allowed, h.Version) {
+			return nil, fmt.Errorf("%w: %q", ErrDisallowedVersion, h.Version)
+		}
+
+		// Check arguments
+		if keys == nil {
+			return nil, errors.New("paseto: key provider is nil")
+		}
+
+		return keys.ResolveKey(h, kid)
+	})
+}
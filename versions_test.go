@@ -0,0 +1,92 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"crypto/rand"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+
+	pasetov4 "zntr.io/paseto/v4"
+	pasetov4x "zntr.io/paseto/v4x"
+)
+
+func TestCheckVersion(t *testing.T) {
+	testCases := []struct {
+		name    string
+		token   string
+		allowed []string
+		want    string
+		wantErr error
+	}{
+		{name: "allowed", token: "v4.local.AAAA", allowed: []string{"v4"}, want: "v4.local."},
+		{name: "one of allowed", token: "v3.public.AAAA", allowed: []string{"v3", "v4"}, want: "v3.public."},
+		{name: "experimental variant", token: "v4x.local.AAAA", allowed: []string{"v4"}, wantErr: ErrDisallowedVersion},
+		{name: "none allowed", token: "v4.local.AAAA", wantErr: ErrDisallowedVersion},
+		{name: "invalid token", token: "v2.local.AAAA", allowed: []string{"v2"}, wantErr: ErrInvalidToken},
+	}
+
+	for _, tc := range testCases {
+		testCase := tc
+		t.Run(testCase.name, func(t *testing.T) {
+			h, err := CheckVersion(testCase.token, testCase.allowed...)
+			if testCase.wantErr != nil {
+				assert.ErrorIs(t, err, testCase.wantErr)
+				return
+			}
+			assert.NoError(t, err)
+			assert.Equal(t, testCase.want, h.Prefix)
+		})
+	}
+}
+
+func TestWithAllowedVersions(t *testing.T) {
+	k4, err := pasetov4.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+	k4x, err := pasetov4x.GenerateLocalKey(rand.Reader)
+	assert.NoError(t, err)
+
+	keys := KeyProviderFunc(func(h Header, _ string) (any, error) {
+		if h.Version == "v4x" {
+			return k4x, nil
+		}
+		return k4, nil
+	})
+	m := []byte(`{"sub":"user-1"}`)
+
+	t4, err := pasetov4.Encrypt(rand.Reader, k4, m, nil, nil)
+	assert.NoError(t, err)
+	t4x, err := pasetov4x.Encrypt(rand.Reader, k4x, m, nil, nil)
+	assert.NoError(t, err)
+
+	// Both are accepted without restriction
+	_, err = DecodeAndValidate(t4x, keys)
+	assert.NoError(t, err)
+
+	// Only v4
+	c, err := DecodeAndValidate(t4, WithAllowedVersions(keys, "v4"))
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", c.Subject)
+	_, err = DecodeAndValidate(t4x, WithAllowedVersions(keys, "v4"))
+	assert.ErrorIs(t, err, ErrDisallowedVersion)
+
+	// Nil provider
+	_, err = DecodeAndValidate(t4, WithAllowedVersions(nil, "v4"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,52 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import "runtime"
+
+// Wipe overwrites the given buffer with zeros. The time taken only depends on
+// the buffer length, not on its content.
+//
+// It is intended to scrub decrypted payloads holding sensitive data (PII) as
+// soon as they are no longer needed instead of waiting for the garbage
+// collector:
+//
+//	payload, err := pasetov4.Decrypt(key, token, f, i)
+//	if err != nil {
+//		return err
+//	}
+//	defer paseto.Wipe(payload)
+//
+//	var c claims.Claims
+//	if err := json.Unmarshal(payload, &c); err != nil {
+//		return err
+//	}
+//
+// The decoded values (strings, maps) are copies that Wipe can't reach, only
+// the raw plaintext is scrubbed. Buffers given to json.Unmarshal and friends
+// are not retained, so they can be wiped right after decoding.
+// DecodeAndValidate wipes the plaintext itself once the claims are decoded.
+//
+// Go doesn't guarantee that no other copy of the data exists (stack growth,
+// moved buffers), Wipe reduces the exposure window but is not a guarantee.
+func Wipe(b []byte) {
+	clear(b)
+
+	// Prevent the stores from being optimized away
+	runtime.KeepAlive(b)
+}
@@ -0,0 +1,41 @@
+// Licensed to SolID under one or more contributor
+// license agreements. See the NOTICE file distributed with
+// this work for additional information regarding copyright
+// ownership. SolID licenses this file to you under
+// the Apache License, Version 2.0 (the "License"); you may
+// not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing,
+// software distributed under the License is distributed on an
+// "AS IS" BASIS, WITHOUT WARRANTIES OR CONDITIONS OF ANY
+// KIND, either express or implied.  See the License for the
+// specific language governing permissions and limitations
+// under the License.
+
+package paseto
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWipe(t *testing.T) {
+	b := []byte("{\"sub\":\"user-1\",\"email\":\"john@example.com\"}")
+	Wipe(b)
+	assert.Equal(t, make([]byte, len(b)), b)
+
+	// Only the given window is wiped
+	b = []byte("0123456789")
+	Wipe(b[2:4])
+	assert.Equal(t, []byte("01\x00\x00456789"), b)
+
+	// Nil and empty buffers
+	assert.NotPanics(t, func() {
+		Wipe(nil)
+		Wipe([]byte{})
+	})
+}